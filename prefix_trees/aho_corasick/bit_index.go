@@ -0,0 +1,34 @@
+package aho_corasick
+
+import "math/bits"
+
+// bitIndex - битовая маска для хранения 256 индексов дочерних узлов.
+type bitIndex [4]uint64
+
+func (b *bitIndex) set(n byte) {
+	hi, lo := b.splitN(n)
+	b[hi] = b[hi] | (1 << lo)
+}
+
+func (b *bitIndex) isSet(n byte) bool {
+	hi, lo := b.splitN(n)
+
+	return b[hi]&(1<<lo) != 0
+}
+
+// getOneNumber возвращает порядковый номер установленного бита. Перед вызовом функции
+// необходимо обязательно проверить установлен ли бит с помощью функции isSet.
+func (b *bitIndex) getOneNumber(n byte) int {
+	hi, lo := b.splitN(n)
+
+	index := bits.OnesCount64(b[hi] & ^(uint64(0xFFFFFFFFFFFFFFFF) << lo))
+	for i := byte(0); i < hi; i++ {
+		index += bits.OnesCount64(b[i])
+	}
+
+	return index
+}
+
+func (b *bitIndex) splitN(n byte) (byte, byte) {
+	return n >> 6, n & 0x3F
+}
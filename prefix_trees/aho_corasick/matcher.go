@@ -0,0 +1,305 @@
+// Package aho_corasick содержит поиск всех вхождений набора ключей в тексте
+// алгоритмом Ахо-Корасик.
+//
+// Автомат строится на устройстве узлов byte_trie.Array: дочерние узлы хранятся
+// в массиве переменной длины с индексацией по 256-битной маске. Дополнительно
+// каждый узел хранит ссылку неудачи (узел самого длинного собственного суффикса
+// пути, который является префиксом одного из ключей) и ссылку выхода (ближайший
+// по ссылкам неудачи узел со значением). Текст просматривается один раз,
+// переход по байту выполняется за амортизированное O(1).
+package aho_corasick
+
+import (
+	"errors"
+	"io"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// ErrUnknownMode - неизвестный режим поиска.
+var ErrUnknownMode = errors.New("unknown match mode")
+
+// Mode - режим выдачи найденных вхождений.
+type Mode int
+
+const (
+	// Overlapping - выдаются все вхождения всех ключей, в том числе пересекающиеся
+	// и вложенные, в порядке возрастания позиции конца вхождения.
+	Overlapping Mode = iota
+	// LeftmostLongest - выдаются непересекающиеся вхождения: из вхождений, начинающихся
+	// в самой левой позиции, выбирается самое длинное, и поиск продолжается после него.
+	LeftmostLongest
+)
+
+// readSize - размер части текста, читаемой из io.Reader за один раз.
+const readSize = 32 * 1024
+
+// Match - вхождение ключа в текст. Start и End - смещения байт начала и конца
+// (не включая) вхождения от начала текста.
+type Match[V any] struct {
+	Start int
+	End   int
+	Value V
+}
+
+// Matcher автомат Ахо-Корасик для поиска ключей словаря в тексте.
+// После построения автомат не изменяется и безопасен для одновременного
+// использования из нескольких горутин.
+type Matcher[V any] struct {
+	root  node[V]
+	mode  Mode
+	count int
+}
+
+// New строит автомат по ключам и значениям словаря dictionary. Пустой ключ
+// игнорируется, так как не имеет вхождений.
+func New[V any](dictionary prefix_trees.Reader[[]byte, V], mode Mode) (*Matcher[V], error) {
+	if mode != Overlapping && mode != LeftmostLongest {
+		return nil, ErrUnknownMode
+	}
+
+	m := &Matcher[V]{mode: mode}
+	err := dictionary.Walk(func(key []byte, value V) error {
+		if len(key) > 0 {
+			m.put(key, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.link()
+
+	return m, nil
+}
+
+// Count возвращает количество ключей в автомате.
+func (m *Matcher[V]) Count() int {
+	return m.count
+}
+
+// FindAll просматривает текст text и для каждого вхождения ключа вызывает функцию f.
+// Перебор прерывается при первой ошибке, которая возвращается из метода.
+func (m *Matcher[V]) FindAll(text []byte, f func(match Match[V]) error) error {
+	s := scanner[V]{matcher: m, node: &m.root}
+	if _, err := s.scan(text, 0, 0, f); err != nil {
+		return err
+	}
+
+	return s.finish(text, 0, f)
+}
+
+// Scan читает текст из r до конца и для каждого вхождения ключа вызывает функцию f.
+// В памяти удерживается только часть текста, которая может понадобиться для выбора
+// вхождения в режиме LeftmostLongest (не длиннее самого длинного ключа).
+func (m *Matcher[V]) Scan(r io.Reader, f func(match Match[V]) error) error {
+	s := scanner[V]{matcher: m, node: &m.root}
+	text := make([]byte, 0, readSize)
+	base := 0
+
+	for {
+		if cap(text)-len(text) < readSize/2 {
+			grown := make([]byte, len(text), 2*cap(text))
+			copy(grown, text)
+			text = grown
+		}
+
+		n, err := r.Read(text[len(text):cap(text)])
+		if n > 0 {
+			from := len(text)
+			text = text[:from+n]
+			keep, scanErr := s.scan(text, base, from, f)
+			if scanErr != nil {
+				return scanErr
+			}
+			// байты до позиции keep больше не понадобятся
+			text = text[:copy(text, text[keep:])]
+			base += keep
+		}
+		if errors.Is(err, io.EOF) {
+			return s.finish(text, base, f)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// put добавляет ключ в дерево автомата.
+func (m *Matcher[V]) put(key []byte, value V) {
+	n := &m.root
+
+	for depth, k := range key {
+		if !n.bits.isSet(k) {
+			n.bits.set(k)
+			n.insertChildAt(n.bits.getOneNumber(k), k, depth+1)
+		}
+		n = &n.children[n.bits.getOneNumber(k)]
+	}
+
+	if n.value == nil {
+		m.count++
+	}
+	n.value = &value
+}
+
+// link устанавливает ссылки неудачи и выхода обходом дерева в ширину: ссылка узла
+// вычисляется по ссылке его родителя, которая находится ближе к корню.
+func (m *Matcher[V]) link() {
+	root := &m.root
+	root.fail = root
+
+	queue := make([]*node[V], 0, len(root.children))
+	for i := range root.children {
+		child := &root.children[i]
+		child.fail = root
+		queue = append(queue, child)
+	}
+
+	for head := 0; head < len(queue); head++ {
+		parent := queue[head]
+		for i := range parent.children {
+			child := &parent.children[i]
+			child.fail = m.next(parent.fail, child.k)
+			if child.fail.value != nil {
+				child.output = child.fail
+			} else {
+				child.output = child.fail.output
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// next возвращает состояние автомата после перехода из состояния n по байту k.
+// Если перехода нет, то выполняется переход по ссылкам неудачи.
+func (m *Matcher[V]) next(n *node[V], k byte) *node[V] {
+	for n != &m.root && !n.bits.isSet(k) {
+		n = n.fail
+	}
+	if n.bits.isSet(k) {
+		return &n.children[n.bits.getOneNumber(k)]
+	}
+
+	return &m.root
+}
+
+// scanner - состояние просмотра текста, сохраняющееся между его частями.
+type scanner[V any] struct {
+	matcher *Matcher[V]
+	node    *node[V]
+	// найденное, но еще не выданное вхождение в режиме LeftmostLongest
+	pending    Match[V]
+	hasPending bool
+}
+
+// scan просматривает байты text[from:], где text начинается со смещения base
+// от начала текста. Возвращает индекс в text, начиная с которого байты могут
+// понадобиться для повторного просмотра.
+func (s *scanner[V]) scan(text []byte, base, from int, f func(match Match[V]) error) (int, error) {
+	root := &s.matcher.root
+
+	if s.matcher.mode == Overlapping {
+		for i := from; i < len(text); i++ {
+			s.node = s.matcher.next(s.node, text[i])
+			end := base + i + 1
+			for out := s.node.match(); out != nil; out = out.output {
+				if err := f(Match[V]{Start: end - out.depth, End: end, Value: *out.value}); err != nil {
+					return 0, err
+				}
+			}
+		}
+
+		return len(text), nil
+	}
+
+	for i := from; i < len(text); {
+		s.node = s.matcher.next(s.node, text[i])
+		i++
+		end := base + i
+
+		// любое следующее вхождение начинается не левее end - depth, поэтому,
+		// если эта позиция правее начала отложенного вхождения, оно окончательное
+		if s.hasPending && end-s.node.depth > s.pending.Start {
+			if err := f(s.pending); err != nil {
+				return 0, err
+			}
+			// просмотр продолжается сразу после выданного вхождения
+			i = s.pending.End - base
+			s.node = root
+			s.hasPending = false
+			continue
+		}
+
+		// самое длинное вхождение, заканчивающееся в этой позиции
+		if out := s.node.match(); out != nil {
+			start := end - out.depth
+			if !s.hasPending || start < s.pending.Start || start == s.pending.Start && end > s.pending.End {
+				s.pending = Match[V]{Start: start, End: end, Value: *out.value}
+				s.hasPending = true
+			}
+		}
+	}
+
+	if s.hasPending {
+		return s.pending.End - base, nil
+	}
+
+	return len(text), nil
+}
+
+// finish выдает отложенные вхождения после окончания текста.
+func (s *scanner[V]) finish(text []byte, base int, f func(match Match[V]) error) error {
+	for s.hasPending {
+		pending := s.pending
+		s.hasPending = false
+		if err := f(pending); err != nil {
+			return err
+		}
+		s.node = &s.matcher.root
+		if _, err := s.scan(text, base, pending.End-base, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type node[V any] struct {
+	// Символ
+	k byte
+	// Битовая маска для индексации массива нижележащих узлов
+	bits bitIndex
+	// Массив нижележащих узлов переменной длины (на основе слайса)
+	children []node[V]
+	// Ссылка на значение ассоциативного массива
+	value *V
+	// Длина пути от корня до узла
+	depth int
+	// Ссылка неудачи: узел самого длинного собственного суффикса пути узла
+	fail *node[V]
+	// Ссылка выхода: ближайший по ссылкам неудачи узел со значением
+	output *node[V]
+}
+
+func (n *node[V]) insertChildAt(index int, k byte, depth int) {
+	child := node[V]{k: k, depth: depth}
+	if len(n.children) == index {
+		// вставка в конец слайса (расширение массива)
+		n.children = append(n.children, child)
+		return
+	}
+
+	// вставка в середину слайса со смещением элементов > index вправо
+	n.children = append(n.children[:index+1], n.children[index:]...)
+	n.children[index] = child
+}
+
+// match возвращает самый длинный ключ, который заканчивается в состоянии n.
+func (n *node[V]) match() *node[V] {
+	if n.value != nil {
+		return n
+	}
+
+	return n.output
+}
@@ -0,0 +1,267 @@
+package aho_corasick_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/aho_corasick"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestMatcher_FindAll(t *testing.T) {
+	dictionary := newDictionary("he", "she", "his", "hers")
+	tests := []struct {
+		name string
+		mode aho_corasick.Mode
+		text string
+		want []string
+	}{
+		{
+			name: "overlapping",
+			mode: aho_corasick.Overlapping,
+			text: "ushers",
+			want: []string{"she 1:4", "he 2:4", "hers 2:6"},
+		},
+		{
+			name: "leftmost longest",
+			mode: aho_corasick.LeftmostLongest,
+			text: "ushers",
+			want: []string{"she 1:4"},
+		},
+		{
+			name: "leftmost longest after match",
+			mode: aho_corasick.LeftmostLongest,
+			text: "hishers hers",
+			want: []string{"his 0:3", "hers 3:7", "hers 8:12"},
+		},
+		{
+			name: "no matches",
+			mode: aho_corasick.LeftmostLongest,
+			text: "abc",
+			want: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := aho_corasick.New[string](dictionary, test.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.want, findAll(t, m, test.text))
+			assert.Equal(t, test.want, scan(t, m, test.text))
+		})
+	}
+}
+
+func TestMatcher_LeftmostLongest_PrefersLongerKey(t *testing.T) {
+	m, err := aho_corasick.New[string](newDictionary("abc", "abcd", "bcde", "e"), aho_corasick.LeftmostLongest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"abcd 0:4", "e 4:5"}, findAll(t, m, "abcde"))
+}
+
+func TestMatcher_Countries(t *testing.T) {
+	dictionary := &byte_trie.Array[int]{}
+	for i, country := range fixtures.Countries {
+		dictionary.Put([]byte(country), i)
+	}
+	m, err := aho_corasick.New[int](dictionary, aho_corasick.LeftmostLongest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "Flights from Bosnia and Herzegovina to Chad via Nigeria and Niger."
+
+	var found []string
+	err = m.FindAll([]byte(text), func(match aho_corasick.Match[int]) error {
+		found = append(found, fixtures.Countries[match.Value])
+		assert.Equal(t, fixtures.Countries[match.Value], text[match.Start:match.End])
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bosnia and Herzegovina", "Chad", "Nigeria", "Niger"}, found)
+}
+
+func TestMatcher_RandomText(t *testing.T) {
+	for _, mode := range []aho_corasick.Mode{aho_corasick.Overlapping, aho_corasick.LeftmostLongest} {
+		for i := 0; i < 50; i++ {
+			keys := make([]string, 1+rand.Intn(10))
+			for j := range keys {
+				keys[j] = randomString(1 + rand.Intn(4))
+			}
+			text := randomString(rand.Intn(200))
+			m, err := aho_corasick.New[string](newDictionary(keys...), mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := naiveFind(keys, text, mode)
+			assert.Equal(t, want, findAll(t, m, text), "keys %q, text %q", keys, text)
+			assert.Equal(t, want, scan(t, m, text), "keys %q, text %q", keys, text)
+		}
+	}
+}
+
+func TestMatcher_StopOnError(t *testing.T) {
+	m, err := aho_corasick.New[string](newDictionary("a"), aho_corasick.Overlapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := errors.New("stop")
+	calls := 0
+
+	err = m.Scan(strings.NewReader("aaaa"), func(match aho_corasick.Match[string]) error {
+		calls++
+		return stop
+	})
+
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestNew_UnknownMode(t *testing.T) {
+	_, err := aho_corasick.New[string](newDictionary("a"), aho_corasick.Mode(10))
+
+	assert.ErrorIs(t, err, aho_corasick.ErrUnknownMode)
+}
+
+func BenchmarkMatcher_FindAll(b *testing.B) {
+	dictionary := &byte_trie.Array[int]{}
+	for i, country := range fixtures.Countries {
+		dictionary.Put([]byte(country), i)
+	}
+	var text bytes.Buffer
+	for text.Len() < 1<<20 {
+		text.WriteString("Flights from Bosnia and Herzegovina to Chad via Nigeria, Niger and the United States. ")
+	}
+
+	for _, mode := range []struct {
+		name string
+		mode aho_corasick.Mode
+	}{
+		{name: "overlapping", mode: aho_corasick.Overlapping},
+		{name: "leftmost longest", mode: aho_corasick.LeftmostLongest},
+	} {
+		m, err := aho_corasick.New[int](dictionary, mode.mode)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(mode.name, func(b *testing.B) {
+			b.SetBytes(int64(text.Len()))
+			for i := 0; i < b.N; i++ {
+				_ = m.FindAll(text.Bytes(), func(match aho_corasick.Match[int]) error {
+					return nil
+				})
+			}
+		})
+	}
+}
+
+func newDictionary(keys ...string) *byte_trie.Array[string] {
+	dictionary := &byte_trie.Array[string]{}
+	for _, key := range keys {
+		dictionary.Put([]byte(key), key)
+	}
+
+	return dictionary
+}
+
+func findAll(t *testing.T, m *aho_corasick.Matcher[string], text string) []string {
+	t.Helper()
+
+	var found []string
+	err := m.FindAll([]byte(text), func(match aho_corasick.Match[string]) error {
+		found = append(found, format(match))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	return found
+}
+
+// scan просматривает текст, читая его по одному байту, чтобы проверить
+// сохранение состояния между частями текста.
+func scan(t *testing.T, m *aho_corasick.Matcher[string], text string) []string {
+	t.Helper()
+
+	var found []string
+	err := m.Scan(iotest.OneByteReader(strings.NewReader(text)), func(match aho_corasick.Match[string]) error {
+		found = append(found, format(match))
+		return nil
+	})
+	assert.NoError(t, err)
+
+	return found
+}
+
+// naiveFind находит вхождения перебором всех позиций текста.
+func naiveFind(keys []string, text string, mode aho_corasick.Mode) []string {
+	var found []string
+
+	if mode == aho_corasick.Overlapping {
+		for end := 1; end <= len(text); end++ {
+			// вхождения с общим концом выдаются от самого длинного
+			for start := 0; start < end; start++ {
+				if contains(keys, text[start:end]) {
+					found = append(found, formatMatch(text[start:end], start, end))
+				}
+			}
+		}
+		return found
+	}
+
+	for start := 0; start < len(text); {
+		end := -1
+		for e := len(text); e > start; e-- {
+			if contains(keys, text[start:e]) {
+				end = e
+				break
+			}
+		}
+		if end < 0 {
+			start++
+			continue
+		}
+		found = append(found, formatMatch(text[start:end], start, end))
+		start = end
+	}
+
+	return found
+}
+
+func contains(keys []string, s string) bool {
+	for _, key := range keys {
+		if key == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func format(match aho_corasick.Match[string]) string {
+	return formatMatch(match.Value, match.Start, match.End)
+}
+
+func formatMatch(key string, start, end int) string {
+	return fmt.Sprintf("%s %d:%d", key, start, end)
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = "ab"[rand.Intn(2)]
+	}
+
+	return string(b)
+}
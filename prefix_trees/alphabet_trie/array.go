@@ -1,9 +1,9 @@
 package alphabet_trie
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
+
+	"github.com/strider2038/algos/prefix_trees"
 )
 
 // Array64 префиксное дерево с оптимизацией памяти и произвольным словарем символов.
@@ -19,6 +19,8 @@ type Array64[V any] struct {
 	indices []int8
 	root    array64Node[V]
 	count   int
+	// кодек значений для бинарной сериализации (см. SetValueCodec)
+	codec prefix_trees.ValueCodec[V]
 }
 
 func NewArray64[V any](alphabet string) *Array64[V] {
@@ -139,45 +141,54 @@ func (array *Array64[V]) Delete(key string) {
 
 // Walk перебирает дерево и для каждого существующего узла вызывает функцию f.
 func (array *Array64[V]) Walk(f func(key string, value V) error) error {
-	return array.root.walk("", f)
+	return array.WalkPrefix("", f)
 }
 
-func (array *Array64[V]) MarshalJSON() ([]byte, error) {
-	var data bytes.Buffer
-	data.WriteRune('{')
-	i := 0
+// WalkPrefix перебирает в порядке алфавита все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (array *Array64[V]) WalkPrefix(prefix string, f func(key string, value V) error) error {
+	node := &array.root
 
-	err := array.Walk(func(key string, value V) error {
-		if i > 0 {
-			data.WriteRune(',')
+	for _, char := range prefix {
+		index := array.getCharIndex(char)
+		// если индекс отсутствует в маске, то таких элементов нет в дереве
+		if !node.bits.isSet(index) {
+			return nil
 		}
+		// по номеру символа находим индекс следующего подузла дерева
+		i := node.bits.getOneNumber(index)
+		node = &node.children[i]
+	}
 
-		k, _ := json.Marshal(key)
-		data.Write(k)
-
-		data.WriteRune(':')
-		v, err := json.Marshal(value)
-		if err != nil {
+	if node.value != nil {
+		if err := f(prefix, *node.value); err != nil {
 			return err
 		}
+	}
 
-		data.Write(v)
+	return node.walk(prefix, f)
+}
 
-		i++
+// KeysWithPrefix возвращает в порядке алфавита ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (array *Array64[V]) KeysWithPrefix(prefix string, limit int) []string {
+	return prefix_trees.KeysWithPrefix(array.WalkPrefix, prefix, limit)
+}
 
-		return nil
-	})
-	if err != nil {
-		return nil, err
+// hasChars возвращает true, если все символы ключа входят в алфавит дерева.
+func (array *Array64[V]) hasChars(key string) bool {
+	for _, char := range key {
+		if int(char) >= len(array.indices) || array.indices[char] < 0 {
+			return false
+		}
 	}
-	data.WriteRune('}')
 
-	return data.Bytes(), nil
+	return true
 }
 
 // getCharIndex возвращает порядковый номер символа из алфавитной таблицы.
 func (array *Array64[V]) getCharIndex(char rune) int8 {
-	if int(char) > len(array.indices) {
+	if int(char) >= len(array.indices) {
 		panic(fmt.Sprintf("index out of range: char '%c'", char))
 	}
 
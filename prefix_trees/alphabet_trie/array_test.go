@@ -1,74 +1,21 @@
 package alphabet_trie_test
 
 import (
-	"encoding/json"
+	"fmt"
 	"testing"
 
-	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
 	"github.com/strider2038/algos/prefix_trees/alphabet_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
 	"github.com/strider2038/algos/testdata/fixtures"
 )
 
-func TestArray64_Basic(t *testing.T) {
-	items := alphabet_trie.NewArray64[int]("abcdefghijklmnopqrstuvwxyz")
-
-	items.Put("alpha", 1)
-	items.Put("beta", 2)
-	items.Put("gamma", 3)
-	items.Put("delta", 4)
-	items.Delete("beta")
-	items.Put("beta", 5)
-	items.Put("cap", 6)
-	items.Put("cat", 7)
-	items.Put("car", 8)
-	items.Delete("delta")
-	items.Delete("delta")
-	items.Delete("unknown")
-
-	assert.Equal(t, 6, items.Count())
-	assert.Equal(t, 1, items.Get("alpha"))
-	assert.Equal(t, 5, items.Get("beta"))
-	assert.Equal(t, 3, items.Get("gamma"))
-	assert.Equal(t, 6, items.Get("cap"))
-	assert.Equal(t, 7, items.Get("cat"))
-	assert.Equal(t, 8, items.Get("car"))
-	assert.Equal(t, 0, items.Get("delta"))
-	if _, exist := items.Find("delta"); exist {
-		t.Error("delta value is found in map")
-	}
-}
-
-func TestArray64_RealData(t *testing.T) {
-	countries := alphabet_trie.NewArray64[int](`abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ "-&`)
-	m := map[string]int{}
-
-	for i, country := range fixtures.Countries {
-		countries.Put(country, i+1)
-		m[country] = i + 1
-	}
-
-	countries.Walk(func(key string, value int) error {
-		assert.Equal(t, m[key], value)
-
-		return nil
+func TestArray64_Conformance(t *testing.T) {
+	trietest.Run(t, func() prefix_trees.Trie[string, int] {
+		return alphabet_trie.NewArray64[int](trietest.Alphabet)
 	})
 }
 
-func TestArray64_MarshalJSON(t *testing.T) {
-	items := alphabet_trie.NewArray64[int]("abcdefghijklmnopqrstuvwxyz")
-	items.Put("alpha", 1)
-	items.Put("beta", 2)
-	items.Put("gamma", 3)
-	items.Put("delta", 4)
-
-	data, err := json.Marshal(items)
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	assert.Equal(t, `{"alpha":1,"beta":2,"delta":4,"gamma":3}`, string(data))
-}
-
 func BenchmarkArray64_Fill(b *testing.B) {
 	cities := fixtures.CitiesT(b)
 	b.ResetTimer()
@@ -119,3 +66,49 @@ func BenchmarkArray64_Get(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkArray64_KeysWithPrefix(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	t := alphabet_trie.NewArray64[int](`abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ "-&`)
+	for n, city := range cities {
+		t.Put(city, n+1)
+	}
+
+	b.ResetTimer()
+
+	for _, prefix := range []string{"S", "San", "San Francisco"} {
+		b.Run(prefix, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				keys := t.KeysWithPrefix(prefix, 10)
+				if len(keys) == 0 {
+					b.Fatal("elements not found")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkArray64_FuzzySearch(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	t := alphabet_trie.NewArray64[int](`abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ "-&`)
+	for n, city := range cities {
+		t.Put(city, n+1)
+	}
+
+	b.ResetTimer()
+
+	for _, maxDistance := range []int{1, 2} {
+		b.Run(fmt.Sprintf("distance %d", maxDistance), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				found := false
+				_ = t.FuzzySearch("Sna Francisco", maxDistance, func(key string, value int, distance int) error {
+					found = true
+					return nil
+				})
+				if !found {
+					b.Fatal("element not found")
+				}
+			}
+		})
+	}
+}
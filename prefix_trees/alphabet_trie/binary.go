@@ -0,0 +1,187 @@
+package alphabet_trie
+
+import (
+	"math/bits"
+	"unicode/utf8"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/binfmt"
+)
+
+// flagValue - флаг наличия значения узла в бинарном формате.
+const flagValue = 1
+
+// SetValueCodec устанавливает кодек значений для бинарной сериализации.
+// По умолчанию используется prefix_trees.JSONCodec.
+func (array *Array64[V]) SetValueCodec(codec prefix_trees.ValueCodec[V]) {
+	array.codec = codec
+}
+
+// MarshalBinary сохраняет дерево в компактном бинарном формате. Сначала записывается
+// таблица индексов алфавита, затем узлы в порядке обхода в глубину: флаги узла,
+// значение и битовая маска дочерних узлов. Данные содержат заголовок с версией
+// формата и контрольную сумму.
+func (array *Array64[V]) MarshalBinary() ([]byte, error) {
+	e := binfmt.NewEncoder(binfmt.KindAlphabetTrie)
+
+	// таблица индексов: пары из порядкового номера и символа
+	size := 0
+	for _, index := range array.indices {
+		if index >= 0 {
+			size++
+		}
+	}
+	e.Uvarint(uint64(size))
+	for char, index := range array.indices {
+		if index >= 0 {
+			e.Byte(byte(index))
+			e.Uvarint(uint64(char))
+		}
+	}
+
+	e.Uvarint(uint64(array.count))
+	if err := array.root.encode(e, array.valueCodec()); err != nil {
+		return nil, err
+	}
+
+	return e.Finish(), nil
+}
+
+// UnmarshalBinary восстанавливает дерево вместе с алфавитом из данных, сохраненных
+// методом MarshalBinary, заменяя текущее содержимое. При ошибке дерево не изменяется.
+func (array *Array64[V]) UnmarshalBinary(data []byte) error {
+	d, err := binfmt.NewDecoder(data, binfmt.KindAlphabetTrie)
+	if err != nil {
+		return err
+	}
+
+	// символы алфавита по порядковым номерам
+	var chars [64]rune
+	var known bitIndex
+	maxChar := rune(-1)
+	size := d.Uvarint()
+	if size > 64 {
+		d.Fail("too big alphabet")
+	}
+	for i := uint64(0); i < size && d.Err() == nil; i++ {
+		index := int8(d.Byte())
+		char := d.Uvarint()
+		if index < 0 || index >= 64 || known.isSet(index) || char > utf8.MaxRune {
+			d.Fail("invalid alphabet")
+			break
+		}
+		known.set(index)
+		chars[index] = rune(char)
+		if rune(char) > maxChar {
+			maxChar = rune(char)
+		}
+	}
+
+	var indices []int8
+	if d.Err() == nil && size > 0 {
+		indices = make([]int8, maxChar+1)
+		for i := range indices {
+			indices[i] = -1
+		}
+		for index := int8(0); index < 64; index++ {
+			if !known.isSet(index) {
+				continue
+			}
+			if indices[chars[index]] >= 0 {
+				d.Fail("duplicate alphabet char")
+				break
+			}
+			indices[chars[index]] = index
+		}
+	}
+
+	count := d.Uvarint()
+	var root array64Node[V]
+	if root.decode(d, array.valueCodec(), &chars, known) != count {
+		d.Fail("values count mismatch")
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+
+	array.indices = indices
+	array.root = root
+	array.count = int(count)
+
+	return nil
+}
+
+func (array *Array64[V]) valueCodec() prefix_trees.ValueCodec[V] {
+	if array.codec == nil {
+		return prefix_trees.JSONCodec[V]{}
+	}
+
+	return array.codec
+}
+
+func (node *array64Node[V]) encode(e *binfmt.Encoder, codec prefix_trees.ValueCodec[V]) error {
+	if node.value == nil {
+		e.Byte(0)
+	} else {
+		e.Byte(flagValue)
+		if err := binfmt.WriteValue(e, codec, *node.value); err != nil {
+			return err
+		}
+	}
+
+	e.Uvarint(uint64(node.bits))
+	for i := range node.children {
+		if err := node.children[i].encode(e, codec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decode читает узел и его поддерево. Символы дочерних узлов восстанавливаются
+// по таблице chars, маска known содержит порядковые номера символов алфавита.
+// Возвращает количество прочитанных значений.
+func (node *array64Node[V]) decode(
+	d *binfmt.Decoder,
+	codec prefix_trees.ValueCodec[V],
+	chars *[64]rune,
+	known bitIndex,
+) uint64 {
+	count := uint64(0)
+
+	flags := d.Byte()
+	if flags&^flagValue != 0 {
+		d.Fail("invalid node flags")
+	}
+	if flags&flagValue != 0 {
+		value := binfmt.ReadValue(d, codec)
+		node.value = &value
+		count++
+	}
+
+	node.bits = bitIndex(d.Uvarint())
+	if node.bits&^known != 0 {
+		d.Fail("char out of alphabet")
+	}
+	if d.Err() != nil || node.bits == 0 {
+		return count
+	}
+
+	// дочерние узлы восстанавливаются по маске в порядке номеров символов
+	node.children = make([]array64Node[V], 0, bits.OnesCount64(uint64(node.bits)))
+	for index := int8(0); index < 64; index++ {
+		if node.bits.isSet(index) {
+			node.children = append(node.children, array64Node[V]{char: chars[index]})
+		}
+	}
+
+	for i := range node.children {
+		if d.Err() != nil {
+			return 0
+		}
+		count += node.children[i].decode(d, codec, chars, known)
+	}
+
+	return count
+}
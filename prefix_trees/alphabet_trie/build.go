@@ -0,0 +1,130 @@
+package alphabet_trie
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// ErrUnsortedKeys - ключи для построения дерева не упорядочены в порядке алфавита
+// или повторяются.
+var ErrUnsortedKeys = errors.New("keys are not sorted")
+
+// BuildFromSorted строит дерево со словарем символов alphabet из последовательности
+// ключей, упорядоченных в порядке символов алфавита (в том порядке, в котором
+// их перебирает метод Walk). Если ключи не упорядочены или повторяются,
+// возвращается ошибка ErrUnsortedKeys.
+//
+// В отличие от заполнения методом Put дочерние узлы только добавляются в конец
+// и не сдвигаются, а массив дочерних узлов каждого узла выделяется один раз
+// под точное количество элементов.
+func BuildFromSorted[V any](alphabet string, seq prefix_trees.Seq[string, V]) (*Array64[V], error) {
+	array := NewArray64[V](alphabet)
+	b := sortedBuilder[V]{array: array, spine: make([]spineNode[V], 1, 32)}
+	if err := seq(b.add); err != nil {
+		return nil, err
+	}
+
+	array.root = b.finish()
+	array.count = b.count
+
+	return array, nil
+}
+
+// sortedBuilder строит дерево из упорядоченных ключей по правой границе дерева:
+// открытыми остаются только узлы пути последнего ключа, все узлы левее уже
+// не изменятся. Узел закрывается, когда следующий ключ отходит от его пути,
+// и добавляется в конец дочерних узлов родителя.
+type sortedBuilder[V any] struct {
+	array *Array64[V]
+	// открытые узлы пути последнего ключа: spine[d] - узел на глубине d
+	spine []spineNode[V]
+	// порядковые номера символов последнего ключа
+	previous    []int8
+	previousKey string
+	// буферы символов и их порядковых номеров текущего ключа
+	chars   []rune
+	indices []int8
+	count   int
+}
+
+type spineNode[V any] struct {
+	node array64Node[V]
+	// порядковый номер символа узла
+	index int8
+	// закрытые дочерние узлы (буфер используется повторно для узлов той же глубины)
+	children []array64Node[V]
+}
+
+func (b *sortedBuilder[V]) add(key string, value V) error {
+	b.chars, b.indices = b.chars[:0], b.indices[:0]
+	for _, char := range key {
+		b.chars = append(b.chars, char)
+		b.indices = append(b.indices, b.array.getCharIndex(char))
+	}
+
+	common := 0
+	for common < len(b.indices) && common < len(b.previous) && b.indices[common] == b.previous[common] {
+		common++
+	}
+	if b.count > 0 && (common == len(b.indices) || common < len(b.previous) && b.indices[common] < b.previous[common]) {
+		return fmt.Errorf("%w: %q after %q", ErrUnsortedKeys, key, b.previousKey)
+	}
+	b.closeTo(common)
+
+	for i := common; i < len(b.chars); i++ {
+		b.open(b.chars[i], b.indices[i])
+	}
+	b.spine[len(b.chars)].node.value = &value
+
+	b.previous = append(b.previous[:0], b.indices...)
+	b.previousKey = key
+	b.count++
+
+	return nil
+}
+
+// finish закрывает все узлы и возвращает корень дерева.
+func (b *sortedBuilder[V]) finish() array64Node[V] {
+	b.closeTo(0)
+
+	return b.spine[0].close()
+}
+
+// open открывает узел с символом char на следующей глубине.
+func (b *sortedBuilder[V]) open(char rune, index int8) {
+	if len(b.spine) < cap(b.spine) {
+		// буфер дочерних узлов ранее закрытого узла этой глубины сохраняется
+		b.spine = b.spine[:len(b.spine)+1]
+	} else {
+		b.spine = append(b.spine, spineNode[V]{})
+	}
+	top := &b.spine[len(b.spine)-1]
+	top.node = array64Node[V]{char: char}
+	top.index = index
+	top.children = top.children[:0]
+}
+
+// closeTo закрывает узлы глубже depth.
+func (b *sortedBuilder[V]) closeTo(depth int) {
+	for last := len(b.spine) - 1; last > depth; last-- {
+		index := b.spine[last].index
+		node := b.spine[last].close()
+		b.spine = b.spine[:last]
+		parent := &b.spine[last-1]
+		parent.node.bits.set(index)
+		parent.children = append(parent.children, node)
+	}
+}
+
+// close возвращает узел с массивом дочерних узлов точного размера.
+func (s *spineNode[V]) close() array64Node[V] {
+	node := s.node
+	if len(s.children) > 0 {
+		node.children = make([]array64Node[V], len(s.children))
+		copy(node.children, s.children)
+	}
+
+	return node
+}
@@ -0,0 +1,44 @@
+package alphabet_trie_test
+
+import (
+	"testing"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/alphabet_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestBuildFromSorted_Conformance(t *testing.T) {
+	trietest.RunBuild(t, func() prefix_trees.Trie[string, int] {
+		return alphabet_trie.NewArray64[int](trietest.Alphabet)
+	}, func(seq prefix_trees.Seq[string, int]) (prefix_trees.Trie[string, int], error) {
+		return alphabet_trie.BuildFromSorted(trietest.Alphabet, seq)
+	}, alphabet_trie.ErrUnsortedKeys)
+}
+
+func BenchmarkArray64_BuildFromSorted(b *testing.B) {
+	const alphabet = `abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ "-&`
+	cities := fixtures.CitiesT(b)
+	// ключи упорядочиваются в порядке алфавита перебором заполненного дерева
+	t := alphabet_trie.NewArray64[int](alphabet)
+	for n, city := range cities {
+		t.Put(city, n+1)
+	}
+	keys := make([]string, 0, t.Count())
+	values := make([]int, 0, t.Count())
+	_ = t.Walk(func(key string, value int) error {
+		keys = append(keys, key)
+		values = append(values, value)
+		return nil
+	})
+	seq := prefix_trees.SliceSeq(keys, values)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := alphabet_trie.BuildFromSorted(alphabet, seq); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
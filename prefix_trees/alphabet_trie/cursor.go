@@ -0,0 +1,50 @@
+package alphabet_trie
+
+import (
+	"unicode/utf8"
+
+	"github.com/strider2038/algos/prefix_trees/internal/cursor"
+)
+
+// cursorNode - узел дерева для курсора. Порядок дочерних узлов определяется
+// алфавитом дерева, поэтому узел хранит ссылку на дерево.
+type cursorNode[V any] struct {
+	array *Array64[V]
+	node  *array64Node[V]
+}
+
+func (array *Array64[V]) cursor() *cursor.Cursor[cursorNode[V], V] {
+	return cursor.New[cursorNode[V], V](cursorNode[V]{array: array, node: &array.root})
+}
+
+// Методы перехода между узлами для курсора (интерфейс cursor.Node).
+
+func (n cursorNode[V]) HasValue() bool {
+	return n.node.value != nil
+}
+
+func (n cursorNode[V]) Value() V {
+	return *n.node.value
+}
+
+func (n cursorNode[V]) ChildCount() int {
+	return len(n.node.children)
+}
+
+func (n cursorNode[V]) Child(i int) cursorNode[V] {
+	return cursorNode[V]{array: n.array, node: &n.node.children[i]}
+}
+
+func (n cursorNode[V]) AppendLabel(key []byte) []byte {
+	return utf8.AppendRune(key, n.node.char)
+}
+
+// SeekChild находит дочерний узел по первому символу ключа. Количество дочерних
+// узлов с меньшими индексами символов является индексом первого дочернего узла
+// с большим или равным символом.
+func (n cursorNode[V]) SeekChild(key []byte) (int, int, bool) {
+	char, size := utf8.DecodeRune(key)
+	index := n.array.getCharIndex(char)
+
+	return n.node.bits.getOneNumber(index), size, n.node.bits.isSet(index)
+}
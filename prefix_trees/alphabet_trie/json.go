@@ -0,0 +1,48 @@
+package alphabet_trie
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+func (array *Array64[V]) MarshalJSON() ([]byte, error) {
+	var data bytes.Buffer
+	if err := array.EncodeJSON(&data); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// UnmarshalJSON добавляет в дерево элементы объекта JSON. Существующие значения
+// с другими ключами сохраняются, как при декодировании в map.
+func (array *Array64[V]) UnmarshalJSON(data []byte) error {
+	return array.DecodeJSON(bytes.NewReader(data))
+}
+
+// EncodeJSON записывает дерево в w объектом JSON в порядке алфавита.
+// Элементы записываются по одному, поэтому документ целиком в памяти не строится.
+func (array *Array64[V]) EncodeJSON(w io.Writer, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Encode(w, array.Walk, options...)
+}
+
+// DecodeJSON читает объект JSON из r с помощью потокового декодера и добавляет
+// его элементы в дерево. Способ записи ключей должен совпадать с указанным
+// при кодировании. Ключ с символом вне алфавита возвращает ошибку. При ошибке
+// в дереве остаются элементы, прочитанные до нее.
+func (array *Array64[V]) DecodeJSON(r io.Reader, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Decode(r, func(key string, value V) error {
+		for _, char := range key {
+			if int(char) >= len(array.indices) || array.indices[char] < 0 {
+				return fmt.Errorf("decode trie key %q: char '%c' is out of alphabet", key, char)
+			}
+		}
+		array.Put(key, value)
+
+		return nil
+	}, options...)
+}
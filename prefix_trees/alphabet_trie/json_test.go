@@ -0,0 +1,18 @@
+package alphabet_trie_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/alphabet_trie"
+)
+
+func TestArray64_DecodeJSON_CharOutOfAlphabet(t *testing.T) {
+	items := alphabet_trie.NewArray64[int]("abc")
+
+	err := items.DecodeJSON(strings.NewReader(`{"ab":1,"abz":2}`))
+
+	assert.ErrorContains(t, err, "out of alphabet")
+	assert.Equal(t, 1, items.Get("ab"))
+}
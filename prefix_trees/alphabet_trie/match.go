@@ -0,0 +1,244 @@
+package alphabet_trie
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrInvalidPattern - ошибка разбора шаблона поиска.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// Match перебирает в порядке алфавита все значения, ключи которых соответствуют
+// шаблону pattern, и для каждого из них вызывает функцию f.
+//
+// Поддерживаемые элементы шаблона:
+//
+//	?       любой символ
+//	*       любая последовательность символов (в том числе пустая)
+//	[abc]   любой символ из перечисленных
+//	[a-z]   любой символ из диапазона
+//	[!abc]  любой символ алфавита, кроме перечисленных (также [^abc])
+//	\c      символ c без специального значения
+//
+// Классы символов разрешаются в маски порядковых номеров символов алфавита.
+// Символы, отсутствующие в алфавите, не совпадают ни с одним ключом.
+func (array *Array64[V]) Match(pattern string, f func(key string, value V) error) error {
+	tokens, err := array.compilePattern([]rune(pattern))
+	if err != nil {
+		return err
+	}
+
+	m := matcher[V]{tokens: tokens, f: f}
+	states := m.states(0)
+	states[0] = true
+	m.closure(states)
+
+	return m.match(&array.root, 0)
+}
+
+// patternToken - элемент шаблона: маска допустимых символов алфавита и признак повторения.
+type patternToken struct {
+	mask bitIndex
+	// элемент '*' - допускает любое количество символов из маски
+	star bool
+}
+
+type matcher[V any] struct {
+	tokens []patternToken
+	f      func(key string, value V) error
+	// ключ текущего узла
+	key []rune
+	// множества активных состояний автомата для каждой глубины обхода
+	// (состояние i - распознаны первые i элементов шаблона)
+	stack [][]bool
+}
+
+func (m *matcher[V]) match(node *array64Node[V], depth int) error {
+	states := m.states(depth)
+
+	if node.value != nil && states[len(m.tokens)] {
+		if err := m.f(string(m.key), *node.value); err != nil {
+			return err
+		}
+	}
+
+	// объединяем маски допустимых символов всех активных состояний
+	var allowed bitIndex
+	for i, token := range m.tokens {
+		if states[i] {
+			allowed |= token.mask
+		}
+	}
+	allowed &= node.bits
+
+	// перебираем только дочерние узлы, символы которых допустимы шаблоном
+	for word := uint64(allowed); word != 0; word &= word - 1 {
+		index := int8(bits.TrailingZeros64(word))
+		if !m.step(depth, index) {
+			continue
+		}
+
+		child := &node.children[node.bits.getOneNumber(index)]
+		m.key = append(m.key[:depth], child.char)
+		if err := m.match(child, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// step вычисляет множество состояний на глубине depth+1 после перехода по символу
+// с порядковым номером index. Возвращает false, если ни одно состояние недостижимо.
+func (m *matcher[V]) step(depth int, index int8) bool {
+	states := m.states(depth)
+	next := m.states(depth + 1)
+	for i := range next {
+		next[i] = false
+	}
+
+	reachable := false
+	for i, token := range m.tokens {
+		if !states[i] || !token.mask.isSet(index) {
+			continue
+		}
+		if token.star {
+			next[i] = true
+		} else {
+			next[i+1] = true
+		}
+		reachable = true
+	}
+	m.closure(next)
+
+	return reachable
+}
+
+// closure добавляет в множество состояния, достижимые пропуском элементов '*'.
+func (m *matcher[V]) closure(states []bool) {
+	for i, token := range m.tokens {
+		if states[i] && token.star {
+			states[i+1] = true
+		}
+	}
+}
+
+// states возвращает множество состояний для глубины depth.
+func (m *matcher[V]) states(depth int) []bool {
+	for len(m.stack) <= depth {
+		m.stack = append(m.stack, make([]bool, len(m.tokens)+1))
+	}
+
+	return m.stack[depth]
+}
+
+// compilePattern разбирает шаблон в последовательность элементов.
+func (array *Array64[V]) compilePattern(pattern []rune) ([]patternToken, error) {
+	var tokens []patternToken
+	all := array.alphabetMask()
+
+	for i := 0; i < len(pattern); i++ {
+		var token patternToken
+
+		switch c := pattern[i]; c {
+		case '?':
+			token.mask = all
+		case '*':
+			// последовательные элементы '*' эквивалентны одному
+			if len(tokens) > 0 && tokens[len(tokens)-1].star {
+				continue
+			}
+			token.mask = all
+			token.star = true
+		case '[':
+			end, err := array.compileClass(pattern, i+1, &token.mask)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case '\\':
+			i++
+			if i >= len(pattern) {
+				return nil, fmt.Errorf("%w: trailing escape character", ErrInvalidPattern)
+			}
+			array.setChars(&token.mask, pattern[i], pattern[i])
+		default:
+			array.setChars(&token.mask, c, c)
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// compileClass разбирает класс символов, начинающийся с позиции start (после '['),
+// и заполняет маску. Возвращает позицию закрывающей скобки.
+func (array *Array64[V]) compileClass(pattern []rune, start int, mask *bitIndex) (int, error) {
+	i := start
+	negate := i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^')
+	if negate {
+		i++
+	}
+
+	for first := true; ; first = false {
+		if i >= len(pattern) {
+			return 0, fmt.Errorf("%w: unclosed character class at %d", ErrInvalidPattern, start-1)
+		}
+		// закрывающая скобка в начале класса считается обычным символом
+		if pattern[i] == ']' && !first {
+			break
+		}
+
+		lo := pattern[i]
+		if lo == '\\' && i+1 < len(pattern) {
+			i++
+			lo = pattern[i]
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi = pattern[i+2]
+			i += 2
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("%w: invalid range %c-%c", ErrInvalidPattern, lo, hi)
+		}
+		array.setChars(mask, lo, hi)
+		i++
+	}
+
+	if negate {
+		*mask = ^*mask & array.alphabetMask()
+	}
+
+	return i, nil
+}
+
+// setChars устанавливает в маске порядковые номера символов алфавита из диапазона
+// от lo до hi включительно. Символы вне алфавита пропускаются.
+func (array *Array64[V]) setChars(mask *bitIndex, lo, hi rune) {
+	if lo < 0 {
+		lo = 0
+	}
+	if int(hi) >= len(array.indices) {
+		hi = rune(len(array.indices) - 1)
+	}
+	for c := lo; c <= hi; c++ {
+		if index := array.indices[c]; index >= 0 {
+			mask.set(index)
+		}
+	}
+}
+
+// alphabetMask возвращает маску всех символов алфавита.
+func (array *Array64[V]) alphabetMask() bitIndex {
+	var mask bitIndex
+	for _, index := range array.indices {
+		if index >= 0 {
+			mask.set(index)
+		}
+	}
+
+	return mask
+}
@@ -0,0 +1,59 @@
+package alphabet_trie
+
+// Min возвращает наименьший ключ дерева и его значение. Здесь и далее ключи
+// упорядочены по порядку следования символов в алфавите дерева.
+func (array *Array64[V]) Min() (string, V, bool) {
+	return result(array.cursor().Min())
+}
+
+// Max возвращает наибольший ключ дерева и его значение.
+func (array *Array64[V]) Max() (string, V, bool) {
+	return result(array.cursor().Max())
+}
+
+// Floor возвращает наибольший ключ, меньший или равный key, и его значение.
+// Здесь и далее, если key содержит символы вне алфавита, то положение ключа
+// не определено и возвращается false.
+func (array *Array64[V]) Floor(key string) (string, V, bool) {
+	if !array.hasChars(key) {
+		return notFound[V]()
+	}
+
+	return result(array.cursor().Floor([]byte(key)))
+}
+
+// Ceiling возвращает наименьший ключ, больший или равный key, и его значение.
+func (array *Array64[V]) Ceiling(key string) (string, V, bool) {
+	if !array.hasChars(key) {
+		return notFound[V]()
+	}
+
+	return result(array.cursor().Ceiling([]byte(key)))
+}
+
+// Predecessor возвращает наибольший ключ, строго меньший key, и его значение.
+func (array *Array64[V]) Predecessor(key string) (string, V, bool) {
+	if !array.hasChars(key) {
+		return notFound[V]()
+	}
+
+	return result(array.cursor().Predecessor([]byte(key)))
+}
+
+// Successor возвращает наименьший ключ, строго больший key, и его значение.
+func (array *Array64[V]) Successor(key string) (string, V, bool) {
+	if !array.hasChars(key) {
+		return notFound[V]()
+	}
+
+	return result(array.cursor().Successor([]byte(key)))
+}
+
+func result[V any](key []byte, value V, ok bool) (string, V, bool) {
+	return string(key), value, ok
+}
+
+func notFound[V any]() (string, V, bool) {
+	var zero V
+	return "", zero, false
+}
@@ -0,0 +1,39 @@
+package alphabet_trie_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/alphabet_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+)
+
+func TestArray64_Navigation_UnknownChar(t *testing.T) {
+	items := alphabet_trie.NewArray64[int](trietest.Alphabet)
+	items.Put("Chad", 1)
+	items.Put("Chile", 2)
+
+	// символы вне алфавита: внутри таблицы индексов, за ее пределами, некорректный UTF-8
+	for _, key := range []string{"Ch@d", "Chadж", "\xff", "!"} {
+		navigate := map[string]func(key string) (string, int, bool){
+			"floor":       items.Floor,
+			"ceiling":     items.Ceiling,
+			"predecessor": items.Predecessor,
+			"successor":   items.Successor,
+		}
+		for name, f := range navigate {
+			assert.NotPanics(t, func() {
+				found, value, ok := f(key)
+
+				assert.False(t, ok, "%s(%q)", name, key)
+				assert.Equal(t, "", found)
+				assert.Equal(t, 0, value)
+			}, "%s(%q)", name, key)
+		}
+	}
+
+	found, value, ok := items.Ceiling("Chb")
+	assert.True(t, ok)
+	assert.Equal(t, "Chile", found)
+	assert.Equal(t, 2, value)
+}
@@ -0,0 +1,394 @@
+package art
+
+// node - узел дерева: лист *leaf или внутренний узел одного из четырех размеров.
+type node[V any] interface {
+	// minLeaf возвращает лист с наименьшим ключом в поддереве узла.
+	minLeaf() *leaf[V]
+}
+
+// leaf хранит полный ключ и значение. Лист может заменять целое поддерево
+// с единственным ключом (ленивое раскрытие), поэтому при поиске ключ листа
+// сравнивается целиком.
+type leaf[V any] struct {
+	key   []byte
+	value V
+}
+
+func (l *leaf[V]) minLeaf() *leaf[V] {
+	return l
+}
+
+// innerNode - операции внутреннего узла, общие для всех размеров.
+type innerNode[V any] interface {
+	node[V]
+
+	header() *inner[V]
+	// child возвращает ячейку дочернего узла по байту k или nil.
+	child(k byte) *node[V]
+	// first возвращает дочерний узел с наименьшим байтом.
+	first() node[V]
+	// addChild добавляет дочерний узел. Если узел заполнен, то дочерние узлы
+	// переносятся в узел большего размера, который и возвращается.
+	addChild(k byte, child node[V]) innerNode[V]
+	// removeChild удаляет дочерний узел по байту k, который должен существовать
+	// (ячейка дочернего узла при этом может быть уже очищена). Если дочерних узлов
+	// стало мало, то они переносятся в узел меньшего размера, который и возвращается.
+	removeChild(k byte) innerNode[V]
+	// walk перебирает дочерние узлы в порядке возрастания байт.
+	walk(f func(k byte, child node[V]) error) error
+}
+
+// inner - заголовок внутреннего узла.
+type inner[V any] struct {
+	// сжатый путь: байты ключа между родительским узлом и этим узлом. Ссылается
+	// на ключ одного из листьев поддерева, поэтому не копируется.
+	prefix []byte
+	// лист ключа, который заканчивается в этом узле
+	terminal *leaf[V]
+	// количество дочерних узлов
+	size int
+}
+
+func (n *inner[V]) header() *inner[V] {
+	return n
+}
+
+// пороги уменьшения узлов ниже вместимости меньшего узла, чтобы чередование
+// вставок и удалений на границе не перестраивало узел каждый раз
+const (
+	shrink16  = 3
+	shrink48  = 12
+	shrink256 = 37
+)
+
+// node4 - узел до 4 дочерних узлов с упорядоченными байтами.
+type node4[V any] struct {
+	inner[V]
+	keys     [4]byte
+	children [4]node[V]
+}
+
+func (n *node4[V]) minLeaf() *leaf[V] {
+	if n.terminal != nil {
+		return n.terminal
+	}
+
+	return n.children[0].minLeaf()
+}
+
+func (n *node4[V]) child(k byte) *node[V] {
+	for i := 0; i < n.size; i++ {
+		if n.keys[i] == k {
+			return &n.children[i]
+		}
+	}
+
+	return nil
+}
+
+func (n *node4[V]) first() node[V] {
+	return n.children[0]
+}
+
+func (n *node4[V]) addChild(k byte, child node[V]) innerNode[V] {
+	if n.size == len(n.keys) {
+		grown := &node16[V]{inner: n.inner}
+		copy(grown.keys[:], n.keys[:])
+		copy(grown.children[:], n.children[:])
+
+		return grown.addChild(k, child)
+	}
+
+	i := 0
+	for i < n.size && n.keys[i] < k {
+		i++
+	}
+	copy(n.keys[i+1:], n.keys[i:n.size])
+	copy(n.children[i+1:], n.children[i:n.size])
+	n.keys[i] = k
+	n.children[i] = child
+	n.size++
+
+	return n
+}
+
+func (n *node4[V]) removeChild(k byte) innerNode[V] {
+	for i := 0; i < n.size; i++ {
+		if n.keys[i] == k {
+			copy(n.keys[i:], n.keys[i+1:n.size])
+			copy(n.children[i:], n.children[i+1:n.size])
+			n.size--
+			n.children[n.size] = nil
+			break
+		}
+	}
+
+	return n
+}
+
+func (n *node4[V]) walk(f func(k byte, child node[V]) error) error {
+	for i := 0; i < n.size; i++ {
+		if err := f(n.keys[i], n.children[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// node16 - узел до 16 дочерних узлов с упорядоченными байтами.
+type node16[V any] struct {
+	inner[V]
+	keys     [16]byte
+	children [16]node[V]
+}
+
+func (n *node16[V]) minLeaf() *leaf[V] {
+	if n.terminal != nil {
+		return n.terminal
+	}
+
+	return n.children[0].minLeaf()
+}
+
+func (n *node16[V]) child(k byte) *node[V] {
+	// байты упорядочены, поэтому поиск останавливается на первом большем байте
+	for i := 0; i < n.size && n.keys[i] <= k; i++ {
+		if n.keys[i] == k {
+			return &n.children[i]
+		}
+	}
+
+	return nil
+}
+
+func (n *node16[V]) first() node[V] {
+	return n.children[0]
+}
+
+func (n *node16[V]) addChild(k byte, child node[V]) innerNode[V] {
+	if n.size == len(n.keys) {
+		grown := &node48[V]{inner: n.inner}
+		for i := 0; i < n.size; i++ {
+			grown.index[n.keys[i]] = byte(i + 1)
+			grown.children[i] = n.children[i]
+		}
+
+		return grown.addChild(k, child)
+	}
+
+	i := 0
+	for i < n.size && n.keys[i] < k {
+		i++
+	}
+	copy(n.keys[i+1:], n.keys[i:n.size])
+	copy(n.children[i+1:], n.children[i:n.size])
+	n.keys[i] = k
+	n.children[i] = child
+	n.size++
+
+	return n
+}
+
+func (n *node16[V]) removeChild(k byte) innerNode[V] {
+	for i := 0; i < n.size; i++ {
+		if n.keys[i] == k {
+			copy(n.keys[i:], n.keys[i+1:n.size])
+			copy(n.children[i:], n.children[i+1:n.size])
+			n.size--
+			n.children[n.size] = nil
+			break
+		}
+	}
+	if n.size > shrink16 {
+		return n
+	}
+
+	shrunk := &node4[V]{inner: n.inner}
+	copy(shrunk.keys[:], n.keys[:n.size])
+	copy(shrunk.children[:], n.children[:n.size])
+
+	return shrunk
+}
+
+func (n *node16[V]) walk(f func(k byte, child node[V]) error) error {
+	for i := 0; i < n.size; i++ {
+		if err := f(n.keys[i], n.children[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// node48 - узел до 48 дочерних узлов. Индекс по байту хранит номер ячейки
+// дочернего узла, увеличенный на единицу (ноль - узла нет).
+type node48[V any] struct {
+	inner[V]
+	index    [256]byte
+	children [48]node[V]
+}
+
+func (n *node48[V]) minLeaf() *leaf[V] {
+	if n.terminal != nil {
+		return n.terminal
+	}
+
+	return n.first().minLeaf()
+}
+
+func (n *node48[V]) child(k byte) *node[V] {
+	if i := n.index[k]; i != 0 {
+		return &n.children[i-1]
+	}
+
+	return nil
+}
+
+func (n *node48[V]) first() node[V] {
+	for _, i := range n.index {
+		if i != 0 {
+			return n.children[i-1]
+		}
+	}
+
+	return nil
+}
+
+func (n *node48[V]) addChild(k byte, child node[V]) innerNode[V] {
+	if n.size == len(n.children) {
+		grown := &node256[V]{inner: n.inner}
+		for b, i := range n.index {
+			if i != 0 {
+				grown.children[b] = n.children[i-1]
+			}
+		}
+
+		return grown.addChild(k, child)
+	}
+
+	// ячейки заняты плотно, поэтому новый узел занимает первую свободную
+	n.children[n.size] = child
+	n.index[k] = byte(n.size + 1)
+	n.size++
+
+	return n
+}
+
+func (n *node48[V]) removeChild(k byte) innerNode[V] {
+	i := n.index[k]
+	n.index[k] = 0
+	n.size--
+
+	// последняя ячейка переносится на место освободившейся
+	if last := n.size; int(i-1) != last {
+		n.children[i-1] = n.children[last]
+		for b, j := range n.index {
+			if int(j) == last+1 {
+				n.index[b] = i
+				break
+			}
+		}
+	}
+	n.children[n.size] = nil
+	if n.size > shrink48 {
+		return n
+	}
+
+	shrunk := &node16[V]{inner: n.inner}
+	j := 0
+	for b, i := range n.index {
+		if i != 0 {
+			shrunk.keys[j] = byte(b)
+			shrunk.children[j] = n.children[i-1]
+			j++
+		}
+	}
+
+	return shrunk
+}
+
+func (n *node48[V]) walk(f func(k byte, child node[V]) error) error {
+	for b, i := range n.index {
+		if i == 0 {
+			continue
+		}
+		if err := f(byte(b), n.children[i-1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// node256 - узел с ячейкой для каждого байта.
+type node256[V any] struct {
+	inner[V]
+	children [256]node[V]
+}
+
+func (n *node256[V]) minLeaf() *leaf[V] {
+	if n.terminal != nil {
+		return n.terminal
+	}
+
+	return n.first().minLeaf()
+}
+
+func (n *node256[V]) child(k byte) *node[V] {
+	if n.children[k] != nil {
+		return &n.children[k]
+	}
+
+	return nil
+}
+
+func (n *node256[V]) first() node[V] {
+	for _, child := range n.children {
+		if child != nil {
+			return child
+		}
+	}
+
+	return nil
+}
+
+func (n *node256[V]) addChild(k byte, child node[V]) innerNode[V] {
+	n.children[k] = child
+	n.size++
+
+	return n
+}
+
+func (n *node256[V]) removeChild(k byte) innerNode[V] {
+	n.children[k] = nil
+	n.size--
+	if n.size > shrink256 {
+		return n
+	}
+
+	shrunk := &node48[V]{inner: n.inner}
+	shrunk.size = 0
+	for b, child := range n.children {
+		if child != nil {
+			shrunk.children[shrunk.size] = child
+			shrunk.size++
+			shrunk.index[b] = byte(shrunk.size)
+		}
+	}
+
+	return shrunk
+}
+
+func (n *node256[V]) walk(f func(k byte, child node[V]) error) error {
+	for b, child := range n.children {
+		if child == nil {
+			continue
+		}
+		if err := f(byte(b), child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package art
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTree_NodeGrowAndShrink(t *testing.T) {
+	tree := Tree[int]{}
+	assertKind := func(want string) {
+		t.Helper()
+		assert.Equal(t, want, fmt.Sprintf("%T", tree.root))
+	}
+
+	tree.Put([]byte{0}, 0)
+	assertKind("*art.leaf[int]")
+
+	for i := 1; i < 256; i++ {
+		tree.Put([]byte{byte(i)}, i)
+		switch i + 1 {
+		case 4:
+			assertKind("*art.node4[int]")
+		case 16:
+			assertKind("*art.node16[int]")
+		case 48:
+			assertKind("*art.node48[int]")
+		case 256:
+			assertKind("*art.node256[int]")
+		}
+	}
+
+	for i := 255; i > 0; i-- {
+		tree.Delete([]byte{byte(i)})
+		switch i {
+		case shrink256:
+			assertKind("*art.node48[int]")
+		case shrink48:
+			assertKind("*art.node16[int]")
+		case shrink16:
+			assertKind("*art.node4[int]")
+		case 1:
+			assertKind("*art.leaf[int]")
+		}
+		for j := 0; j < i; j++ {
+			assert.Equal(t, j, tree.Get([]byte{byte(j)}), "at key %d after deleting %d", j, i)
+		}
+	}
+}
+
+func TestTree_Delete_MergesPrefix(t *testing.T) {
+	tree := Tree[int]{}
+	tree.Put([]byte("romane"), 1)
+	tree.Put([]byte("romanus"), 2)
+	tree.Put([]byte("rubens"), 3)
+	tree.Put([]byte("ruber"), 4)
+
+	tree.Delete([]byte("romane"))
+	tree.Delete([]byte("romanus"))
+
+	// корень с единственным дочерним узлом объединяется с ним
+	root, ok := tree.root.(*node4[int])
+	if assert.True(t, ok) {
+		assert.Equal(t, "rube", string(root.prefix))
+		assert.Equal(t, 2, root.size)
+	}
+	assert.Equal(t, 3, tree.Get([]byte("rubens")))
+	assert.Equal(t, 4, tree.Get([]byte("ruber")))
+}
@@ -0,0 +1,339 @@
+// Package art содержит адаптивное радикс-дерево (Adaptive Radix Tree, V. Leis и др.).
+//
+// Внутренние узлы дерева имеют один из четырех размеров (4, 16, 48 и 256 дочерних
+// узлов) и заменяются узлом соседнего размера при росте или уменьшении количества
+// дочерних узлов, поэтому разреженные узлы не занимают память под полный набор
+// ячеек. Цепочки узлов с единственным дочерним узлом сжимаются в префикс узла
+// (сжатие пути), а поддерево с единственным ключом заменяется листом с полным
+// ключом (ленивое раскрытие).
+package art
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+// Tree адаптивное радикс-дерево с байтовыми ключами.
+//
+// Ключ, который является префиксом другого ключа, хранится в листе terminal
+// внутреннего узла, в котором он заканчивается. Нулевое значение является пустым деревом.
+type Tree[V any] struct {
+	root  node[V]
+	count int
+}
+
+func (t *Tree[V]) Count() int {
+	return t.count
+}
+
+func (t *Tree[V]) Get(key []byte) V {
+	v, _ := t.Find(key)
+
+	return v
+}
+
+func (t *Tree[V]) Find(key []byte) (V, bool) {
+	var zero V
+	n := t.root
+	depth := 0
+
+	for n != nil {
+		if l, ok := n.(*leaf[V]); ok {
+			if bytes.Equal(l.key, key) {
+				return l.value, true
+			}
+			return zero, false
+		}
+
+		in := n.(innerNode[V])
+		h := in.header()
+		if !bytes.HasPrefix(key[depth:], h.prefix) {
+			return zero, false
+		}
+		depth += len(h.prefix)
+		if depth == len(key) {
+			if h.terminal == nil {
+				return zero, false
+			}
+			return h.terminal.value, true
+		}
+
+		child := in.child(key[depth])
+		if child == nil {
+			return zero, false
+		}
+		n = *child
+		depth++
+	}
+
+	return zero, false
+}
+
+func (t *Tree[V]) Put(key []byte, value V) {
+	ref := &t.root
+	depth := 0
+
+	for {
+		switch n := (*ref).(type) {
+		case nil:
+			*ref = newLeaf(key, value)
+			t.count++
+			return
+
+		case *leaf[V]:
+			if bytes.Equal(n.key, key) {
+				n.value = value
+				return
+			}
+
+			// лист раскрывается в узел с общей частью двух ключей
+			l := newLeaf(key, value)
+			common := commonPrefix(n.key[depth:], l.key[depth:])
+			split := &node4[V]{}
+			split.prefix = l.key[depth : depth+common]
+			depth += common
+			*ref = attach[V](attach[V](split, n, depth), l, depth)
+			t.count++
+			return
+
+		case innerNode[V]:
+			h := n.header()
+			common := commonPrefix(h.prefix, key[depth:])
+			if common < len(h.prefix) {
+				// ключ расходится с префиксом узла: префикс разделяется новым узлом
+				split := &node4[V]{}
+				split.prefix = h.prefix[:common]
+				split.addChild(h.prefix[common], n)
+				h.prefix = h.prefix[common+1:]
+				*ref = attach[V](split, newLeaf(key, value), depth+common)
+				t.count++
+				return
+			}
+			depth += common
+
+			if depth == len(key) {
+				if h.terminal != nil {
+					h.terminal.value = value
+					return
+				}
+				h.terminal = newLeaf(key, value)
+				t.count++
+				return
+			}
+
+			child := n.child(key[depth])
+			if child == nil {
+				*ref = n.addChild(key[depth], newLeaf(key, value))
+				t.count++
+				return
+			}
+			ref = child
+			depth++
+		}
+	}
+}
+
+// Delete удаляет значение из ассоциативного массива. Узлы с малым количеством
+// дочерних узлов заменяются узлами меньшего размера, а узлы с единственным
+// дочерним узлом объединяются с ним.
+func (t *Tree[V]) Delete(key []byte) {
+	if t.delete(&t.root, key, 0) {
+		t.count--
+	}
+}
+
+// Walk перебирает дерево в порядке возрастания ключей и для каждого существующего
+// значения вызывает функцию f. Слайс ключа используется повторно и должен быть
+// скопирован, если он нужен после возврата из f.
+func (t *Tree[V]) Walk(f func(key []byte, value V) error) error {
+	return t.WalkPrefix(nil, f)
+}
+
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (t *Tree[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	n := t.root
+	depth := 0
+
+	for n != nil {
+		if l, ok := n.(*leaf[V]); ok {
+			if !bytes.HasPrefix(l.key, prefix) {
+				return nil
+			}
+			break
+		}
+
+		in := n.(innerNode[V])
+		h := in.header()
+		rest := prefix[depth:]
+		if len(rest) <= len(h.prefix) {
+			// префикс заканчивается внутри сжатого пути узла
+			if !bytes.HasPrefix(h.prefix, rest) {
+				return nil
+			}
+			break
+		}
+		if !bytes.HasPrefix(rest, h.prefix) {
+			return nil
+		}
+		depth += len(h.prefix)
+
+		child := in.child(prefix[depth])
+		if child == nil {
+			return nil
+		}
+		n = *child
+		depth++
+	}
+	if n == nil {
+		return nil
+	}
+
+	key := make([]byte, depth, depth+32)
+	copy(key, prefix)
+
+	return t.walk(n, key, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (t *Tree[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(t.WalkPrefix, prefix, limit)
+}
+
+func (t *Tree[V]) MarshalJSON() ([]byte, error) {
+	var data bytes.Buffer
+	if err := t.EncodeJSON(&data); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// EncodeJSON записывает дерево в w объектом JSON в порядке возрастания ключей.
+func (t *Tree[V]) EncodeJSON(w io.Writer, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Encode(w, t.Walk, options...)
+}
+
+// walk перебирает поддерево узла n, путь к которому (без сжатого пути узла) равен key.
+func (t *Tree[V]) walk(n node[V], key []byte, f func(key []byte, value V) error) error {
+	if l, ok := n.(*leaf[V]); ok {
+		return f(append(key, l.key[len(key):]...), l.value)
+	}
+
+	in := n.(innerNode[V])
+	h := in.header()
+	key = append(key, h.prefix...)
+	if h.terminal != nil {
+		if err := f(key, h.terminal.value); err != nil {
+			return err
+		}
+	}
+
+	return in.walk(func(k byte, child node[V]) error {
+		return t.walk(child, append(key, k), f)
+	})
+}
+
+// delete удаляет ключ из поддерева в ячейке ref, сжатый путь которого начинается
+// на глубине depth. Возвращает признак того, что ключ был найден.
+func (t *Tree[V]) delete(ref *node[V], key []byte, depth int) bool {
+	switch n := (*ref).(type) {
+	case nil:
+		return false
+
+	case *leaf[V]:
+		if !bytes.Equal(n.key, key) {
+			return false
+		}
+		*ref = nil
+		return true
+
+	case innerNode[V]:
+		h := n.header()
+		if !bytes.HasPrefix(key[depth:], h.prefix) {
+			return false
+		}
+		end := depth + len(h.prefix)
+
+		if end == len(key) {
+			if h.terminal == nil {
+				return false
+			}
+			h.terminal = nil
+		} else {
+			child := n.child(key[end])
+			if child == nil || !t.delete(child, key, end+1) {
+				return false
+			}
+			if *child == nil {
+				n = n.removeChild(key[end])
+			}
+		}
+
+		*ref = collapse(n, depth)
+		return true
+	}
+
+	return false
+}
+
+// collapse заменяет внутренний узел без дочерних узлов его листом terminal,
+// а узел с единственным дочерним узлом и без terminal - этим дочерним узлом.
+// Сжатый путь узла начинается на глубине depth.
+func collapse[V any](n innerNode[V], depth int) node[V] {
+	h := n.header()
+	if h.size == 0 {
+		if h.terminal == nil {
+			return nil
+		}
+		return h.terminal
+	}
+	if h.size > 1 || h.terminal != nil {
+		return n
+	}
+
+	child := n.first()
+	in, ok := child.(innerNode[V])
+	if !ok {
+		return child
+	}
+
+	// сжатый путь дочернего узла удлиняется на путь родителя и байт перехода
+	ch := in.header()
+	end := depth + len(h.prefix) + 1 + len(ch.prefix)
+	ch.prefix = in.minLeaf().key[depth:end]
+
+	return in
+}
+
+// attach добавляет лист l во внутренний узел, путь к которому имеет длину depth.
+func attach[V any](n innerNode[V], l *leaf[V], depth int) innerNode[V] {
+	if len(l.key) == depth {
+		n.header().terminal = l
+		return n
+	}
+
+	return n.addChild(l.key[depth], l)
+}
+
+func newLeaf[V any](key []byte, value V) *leaf[V] {
+	return &leaf[V]{key: append([]byte(nil), key...), value: value}
+}
+
+func commonPrefix(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,162 @@
+package art_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/art"
+	"github.com/strider2038/algos/prefix_trees/byte_shard_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_suffix_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+	"github.com/viant/ptrie"
+)
+
+func TestTree_Conformance(t *testing.T) {
+	trietest.Run(t, func() prefix_trees.Trie[[]byte, int] {
+		return &art.Tree[int]{}
+	})
+}
+
+func BenchmarkTree_Fill(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		t := art.Tree[int]{}
+		for n, city := range cities {
+			t.Put([]byte(city), n+1)
+		}
+	}
+}
+
+func BenchmarkTree_Get(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	t := art.Tree[int]{}
+	for n, city := range cities {
+		t.Put([]byte(city), n+1)
+	}
+
+	b.ResetTimer()
+
+	benchmarks := []struct {
+		name     string
+		cityName string
+	}{
+		{
+			name:     "short name",
+			cityName: "Adville",
+		},
+		{
+			name:     "long name",
+			cityName: "Advocate Lutheran General Childrens Hospital",
+		},
+		{
+			name:     "long unique suffix",
+			cityName: "Advocate Services Medical Transportation",
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, found := t.Find([]byte(bm.cityName))
+				if !found {
+					b.Fatal("element not found")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTree_KeysWithPrefix(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	t := art.Tree[int]{}
+	for n, city := range cities {
+		t.Put([]byte(city), n+1)
+	}
+
+	b.ResetTimer()
+
+	for _, prefix := range []string{"S", "San", "San Francisco"} {
+		b.Run(prefix, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				keys := t.KeysWithPrefix([]byte(prefix), 10)
+				if len(keys) == 0 {
+					b.Fatal("elements not found")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkTree_Memory измеряет объем кучи, занятой деревом с названиями городов,
+// в сравнении с другими вариантами деревьев.
+func BenchmarkTree_Memory(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+
+	implementations := []struct {
+		name string
+		fill func() any
+	}{
+		{
+			name: "art",
+			fill: func() any { return fill(&art.Tree[int]{}, cities) },
+		},
+		{
+			name: "byte trie",
+			fill: func() any { return fill(&byte_trie.Array[int]{}, cities) },
+		},
+		{
+			name: "byte shard trie",
+			fill: func() any { return fill(&byte_shard_trie.Array[int]{}, cities) },
+		},
+		{
+			name: "byte suffix trie",
+			fill: func() any { return fill(&byte_suffix_trie.Array[int]{}, cities) },
+		},
+		{
+			name: "viant",
+			fill: func() any {
+				t := ptrie.New()
+				for n, city := range cities {
+					if err := t.Put([]byte(city), n+1); err != nil {
+						b.Fatal(err)
+					}
+				}
+				return t
+			},
+		},
+	}
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			var heap int64
+			for i := 0; i < b.N; i++ {
+				before := heapInUse()
+				t := impl.fill()
+				heap = heapInUse() - before
+				runtime.KeepAlive(t)
+			}
+			b.ReportMetric(float64(heap), "heap-bytes")
+			b.ReportMetric(float64(heap)/float64(len(cities)), "bytes/key")
+		})
+	}
+}
+
+func fill(t prefix_trees.Trie[[]byte, int], cities []string) prefix_trees.Trie[[]byte, int] {
+	for n, city := range cities {
+		t.Put([]byte(city), n+1)
+	}
+
+	return t
+}
+
+func heapInUse() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return int64(stats.HeapAlloc)
+}
@@ -1,9 +1,6 @@
 package byte_shard_trie
 
-import (
-	"bytes"
-	"encoding/json"
-)
+import "github.com/strider2038/algos/prefix_trees"
 
 // Array префиксное дерево с оптимизацией памяти для хранения данных с произвольными
 // ключами в виде слайса байт.
@@ -16,6 +13,8 @@ import (
 type Array[V any] struct {
 	root  arrayNode[V]
 	count int
+	// кодек значений для бинарной сериализации (см. SetValueCodec)
+	codec prefix_trees.ValueCodec[V]
 }
 
 func (array *Array[V]) Count() int {
@@ -106,40 +105,72 @@ func (array *Array[V]) Delete(key []byte) {
 
 // Walk перебирает дерево и для каждого существующего узла вызывает функцию f.
 func (array *Array[V]) Walk(f func(key []byte, value V) error) error {
-	return array.root.walk(nil, f)
+	return array.WalkPrefix(nil, f)
 }
 
-func (array Array[V]) MarshalJSON() ([]byte, error) {
-	var data bytes.Buffer
-	data.WriteRune('{')
-	i := 0
+// LongestPrefix находит самый длинный из хранящихся в дереве ключей, который является
+// префиксом ключа key. Возвращает длину найденного ключа, его значение и флаг наличия.
+// Сложность O(len(key)).
+func (array *Array[V]) LongestPrefix(key []byte) (int, V, bool) {
+	node := &array.root
+	matchedLen := -1
+	var value V
+
+	if node.value != nil {
+		matchedLen, value = 0, *node.value
+	}
 
-	err := array.Walk(func(key []byte, value V) error {
-		if i > 0 {
-			data.WriteRune(',')
+	for i, k := range key {
+		hi, lo := splitKey(k)
+		// если индекс отсутствует в маске, то более длинных ключей нет
+		if !node.bits[hi].isSet(lo) {
+			break
 		}
+		// по номеру символа находим индекс следующего подузла дерева
+		node = &node.children[hi][node.bits[hi].getOneNumber(lo)]
+		if node.value != nil {
+			matchedLen, value = i+1, *node.value
+		}
+	}
 
-		k, _ := json.Marshal(string(key))
-		data.Write(k)
+	if matchedLen < 0 {
+		return 0, value, false
+	}
 
-		data.WriteRune(':')
-		v, err := json.Marshal(value)
-		if err != nil {
-			return err
-		}
+	return matchedLen, value, true
+}
 
-		data.Write(v)
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (array *Array[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	node := &array.root
 
-		i++
+	for _, k := range prefix {
+		hi, lo := splitKey(k)
+		// если индекс отсутствует в маске, то таких элементов нет в дереве
+		if !node.bits[hi].isSet(lo) {
+			return nil
+		}
+		// по номеру символа находим индекс следующего подузла дерева
+		i := node.bits[hi].getOneNumber(lo)
+		node = &node.children[hi][i]
+	}
 
-		return nil
-	})
-	if err != nil {
-		return nil, err
+	// копируем префикс, чтобы не изменять массив вызывающей стороны при переборе
+	key := append(make([]byte, 0, len(prefix)), prefix...)
+	if node.value != nil {
+		if err := f(key, *node.value); err != nil {
+			return err
+		}
 	}
-	data.WriteRune('}')
 
-	return data.Bytes(), nil
+	return node.walk(key, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (array *Array[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(array.WalkPrefix, prefix, limit)
 }
 
 type arrayNode[V any] struct {
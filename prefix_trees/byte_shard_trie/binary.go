@@ -0,0 +1,174 @@
+package byte_shard_trie
+
+import (
+	"math/bits"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/binfmt"
+)
+
+// maskThreshold - количество дочерних узлов, начиная с которого вместо списка
+// их байт записываются битовые маски всех шардов узла (32 байта).
+const maskThreshold = 32
+
+// flagValue - флаг наличия значения узла в бинарном формате.
+const flagValue = 1
+
+// SetValueCodec устанавливает кодек значений для бинарной сериализации.
+// По умолчанию используется prefix_trees.JSONCodec.
+func (array *Array[V]) SetValueCodec(codec prefix_trees.ValueCodec[V]) {
+	array.codec = codec
+}
+
+// MarshalBinary сохраняет дерево в компактном бинарном формате. Узлы записываются
+// в порядке обхода в глубину: флаги узла, значение, количество дочерних узлов
+// и их байты (или битовые маски шардов для узлов с большим количеством дочерних
+// узлов). Данные содержат заголовок с версией формата и контрольную сумму.
+func (array *Array[V]) MarshalBinary() ([]byte, error) {
+	e := binfmt.NewEncoder(binfmt.KindByteShardTrie)
+	e.Uvarint(uint64(array.count))
+	if err := array.root.encode(e, array.valueCodec()); err != nil {
+		return nil, err
+	}
+
+	return e.Finish(), nil
+}
+
+// UnmarshalBinary восстанавливает дерево из данных, сохраненных методом MarshalBinary,
+// заменяя текущее содержимое. При ошибке дерево не изменяется.
+func (array *Array[V]) UnmarshalBinary(data []byte) error {
+	d, err := binfmt.NewDecoder(data, binfmt.KindByteShardTrie)
+	if err != nil {
+		return err
+	}
+
+	count := d.Uvarint()
+	var root arrayNode[V]
+	if root.decode(d, array.valueCodec()) != count {
+		d.Fail("values count mismatch")
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+
+	array.root = root
+	array.count = int(count)
+
+	return nil
+}
+
+func (array *Array[V]) valueCodec() prefix_trees.ValueCodec[V] {
+	if array.codec == nil {
+		return prefix_trees.JSONCodec[V]{}
+	}
+
+	return array.codec
+}
+
+func (node *arrayNode[V]) encode(e *binfmt.Encoder, codec prefix_trees.ValueCodec[V]) error {
+	if node.value == nil {
+		e.Byte(0)
+	} else {
+		e.Byte(flagValue)
+		if err := binfmt.WriteValue(e, codec, *node.value); err != nil {
+			return err
+		}
+	}
+
+	n := 0
+	for _, shard := range node.children {
+		n += len(shard)
+	}
+	e.Uvarint(uint64(n))
+	if n >= maskThreshold {
+		for _, mask := range node.bits {
+			e.Uint64(uint64(mask))
+		}
+	} else {
+		for _, shard := range node.children {
+			for i := range shard {
+				e.Byte(shard[i].k)
+			}
+		}
+	}
+
+	for _, shard := range node.children {
+		for i := range shard {
+			if err := shard[i].encode(e, codec); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// decode читает узел и его поддерево. Возвращает количество прочитанных значений.
+func (node *arrayNode[V]) decode(d *binfmt.Decoder, codec prefix_trees.ValueCodec[V]) uint64 {
+	count := uint64(0)
+
+	flags := d.Byte()
+	if flags&^flagValue != 0 {
+		d.Fail("invalid node flags")
+	}
+	if flags&flagValue != 0 {
+		value := binfmt.ReadValue(d, codec)
+		node.value = &value
+		count++
+	}
+
+	n := d.Uvarint()
+	if n > 256 {
+		d.Fail("invalid children count")
+	}
+	if d.Err() != nil || n == 0 {
+		return count
+	}
+
+	if n >= maskThreshold {
+		total := 0
+		for hi := range node.bits {
+			node.bits[hi] = bitIndex(d.Uint64())
+			total += bits.OnesCount64(uint64(node.bits[hi]))
+		}
+		if total != int(n) {
+			d.Fail("invalid children mask")
+			return 0
+		}
+	} else {
+		keys := d.Raw(int(n))
+		for i, k := range keys {
+			if i > 0 && k <= keys[i-1] {
+				d.Fail("unordered children")
+				return 0
+			}
+			hi, lo := splitKey(k)
+			node.bits[hi].set(lo)
+		}
+	}
+
+	// дочерние узлы шардов восстанавливаются по маскам в порядке возрастания
+	for hi := range node.children {
+		size := bits.OnesCount64(uint64(node.bits[hi]))
+		if size == 0 {
+			continue
+		}
+		node.children[hi] = make([]arrayNode[V], 0, size)
+		for lo := byte(0); lo < 64; lo++ {
+			if node.bits[hi].isSet(lo) {
+				node.children[hi] = append(node.children[hi], arrayNode[V]{k: byte(hi)<<6 | lo})
+			}
+		}
+	}
+
+	for _, shard := range node.children {
+		for i := range shard {
+			if d.Err() != nil {
+				return 0
+			}
+			count += shard[i].decode(d, codec)
+		}
+	}
+
+	return count
+}
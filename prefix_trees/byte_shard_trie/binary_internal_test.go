@@ -0,0 +1,50 @@
+package byte_shard_trie
+
+import (
+	"math/bits"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArray_UnmarshalBinary_ShardLayout(t *testing.T) {
+	items := &Array[int]{}
+	// корень с битовыми масками шардов и узел со списком байт в разных шардах
+	for i := 0; i < 256; i += 3 {
+		items.Put([]byte{byte(i)}, i)
+	}
+	for _, k := range []byte{0x01, 0x41, 0x81, 0xC1} {
+		items.Put([]byte{0x00, k}, int(k))
+	}
+
+	data, err := items.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &Array[int]{}
+	err = restored.UnmarshalBinary(data)
+
+	assert.NoError(t, err)
+	assertShardLayout(t, &restored.root)
+	assert.Equal(t, 0x81, restored.Get([]byte{0x00, 0x81}))
+}
+
+// assertShardLayout проверяет, что каждый шард узла содержит узлы своих байт
+// в порядке возрастания, а емкость шарда равна его длине.
+func assertShardLayout(t *testing.T, node *arrayNode[int]) {
+	t.Helper()
+
+	for hi, shard := range node.children {
+		assert.Equal(t, bits.OnesCount64(uint64(node.bits[hi])), len(shard))
+		assert.Equal(t, len(shard), cap(shard))
+		for i := range shard {
+			khi, klo := splitKey(shard[i].k)
+			assert.Equal(t, byte(hi), khi)
+			assert.True(t, node.bits[hi].isSet(klo))
+			if i > 0 {
+				assert.Less(t, shard[i-1].k, shard[i].k)
+			}
+			assertShardLayout(t, &shard[i])
+		}
+	}
+}
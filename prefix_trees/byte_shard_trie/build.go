@@ -0,0 +1,125 @@
+package byte_shard_trie
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// ErrUnsortedKeys - ключи для построения дерева не упорядочены по возрастанию
+// или повторяются.
+var ErrUnsortedKeys = errors.New("keys are not sorted")
+
+// BuildFromSorted строит дерево из последовательности ключей, упорядоченных
+// по возрастанию байт (например, из метода Walk другого дерева или prefix_trees.SliceSeq).
+// Если ключи не упорядочены или повторяются, возвращается ошибка ErrUnsortedKeys.
+//
+// В отличие от заполнения методом Put дочерние узлы только добавляются в конец
+// и не сдвигаются, а массивы дочерних узлов каждого узла выделяются одним блоком
+// под точное количество элементов.
+func BuildFromSorted[V any](seq prefix_trees.Seq[[]byte, V]) (*Array[V], error) {
+	b := sortedBuilder[V]{spine: make([]spineNode[V], 1, 32)}
+	if err := seq(b.add); err != nil {
+		return nil, err
+	}
+
+	return &Array[V]{root: b.finish(), count: b.count}, nil
+}
+
+// sortedBuilder строит дерево из упорядоченных ключей по правой границе дерева:
+// открытыми остаются только узлы пути последнего ключа, все узлы левее уже
+// не изменятся. Узел закрывается, когда следующий ключ отходит от его пути,
+// и добавляется в конец дочерних узлов родителя.
+type sortedBuilder[V any] struct {
+	// открытые узлы пути последнего ключа: spine[d] - узел на глубине d
+	spine    []spineNode[V]
+	previous []byte
+	count    int
+}
+
+type spineNode[V any] struct {
+	node arrayNode[V]
+	// закрытые дочерние узлы всех сегментов в порядке возрастания байт
+	// (буфер используется повторно для узлов той же глубины)
+	children []arrayNode[V]
+}
+
+func (b *sortedBuilder[V]) add(key []byte, value V) error {
+	if b.count > 0 && string(key) <= string(b.previous) {
+		return fmt.Errorf("%w: %q after %q", ErrUnsortedKeys, key, b.previous)
+	}
+
+	common := 0
+	for common < len(key) && common < len(b.previous) && key[common] == b.previous[common] {
+		common++
+	}
+	b.closeTo(common)
+
+	for _, k := range key[common:] {
+		b.open(k)
+	}
+	b.spine[len(key)].node.value = &value
+
+	b.previous = append(b.previous[:0], key...)
+	b.count++
+
+	return nil
+}
+
+// finish закрывает все узлы и возвращает корень дерева.
+func (b *sortedBuilder[V]) finish() arrayNode[V] {
+	b.closeTo(0)
+
+	return b.spine[0].close()
+}
+
+// open открывает узел с байтом k на следующей глубине.
+func (b *sortedBuilder[V]) open(k byte) {
+	if len(b.spine) < cap(b.spine) {
+		// буфер дочерних узлов ранее закрытого узла этой глубины сохраняется
+		b.spine = b.spine[:len(b.spine)+1]
+	} else {
+		b.spine = append(b.spine, spineNode[V]{})
+	}
+	top := &b.spine[len(b.spine)-1]
+	top.node = arrayNode[V]{k: k}
+	top.children = top.children[:0]
+}
+
+// closeTo закрывает узлы глубже depth.
+func (b *sortedBuilder[V]) closeTo(depth int) {
+	for last := len(b.spine) - 1; last > depth; last-- {
+		node := b.spine[last].close()
+		b.spine = b.spine[:last]
+		parent := &b.spine[last-1]
+		hi, lo := splitKey(node.k)
+		parent.node.bits[hi].set(lo)
+		parent.children = append(parent.children, node)
+	}
+}
+
+// close возвращает узел, сегменты которого ссылаются на части общего массива
+// дочерних узлов точного размера.
+func (s *spineNode[V]) close() arrayNode[V] {
+	node := s.node
+	if len(s.children) == 0 {
+		return node
+	}
+
+	children := make([]arrayNode[V], len(s.children))
+	copy(children, s.children)
+	// дочерние узлы упорядочены, поэтому каждый сегмент - непрерывный диапазон
+	for start, end := 0, 0; start < len(children); start = end {
+		hi, _ := splitKey(children[start].k)
+		for end = start + 1; end < len(children); end++ {
+			if next, _ := splitKey(children[end].k); next != hi {
+				break
+			}
+		}
+		// емкость ограничена, чтобы добавление в сегмент не затирало соседний
+		node.children[hi] = children[start:end:end]
+	}
+
+	return node
+}
@@ -0,0 +1,43 @@
+package byte_shard_trie_test
+
+import (
+	"testing"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/byte_shard_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestBuildFromSorted_Conformance(t *testing.T) {
+	trietest.RunBuild(t, func() prefix_trees.Trie[[]byte, int] {
+		return &byte_shard_trie.Array[int]{}
+	}, func(seq prefix_trees.Seq[[]byte, int]) (prefix_trees.Trie[[]byte, int], error) {
+		return byte_shard_trie.BuildFromSorted(seq)
+	}, byte_shard_trie.ErrUnsortedKeys)
+}
+
+func BenchmarkArray64_BuildFromSorted(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	// ключи упорядочиваются перебором заполненного дерева
+	t := byte_shard_trie.Array[int]{}
+	for n, city := range cities {
+		t.Put([]byte(city), n+1)
+	}
+	keys := make([][]byte, 0, t.Count())
+	values := make([]int, 0, t.Count())
+	_ = t.Walk(func(key []byte, value int) error {
+		keys = append(keys, append([]byte(nil), key...))
+		values = append(values, value)
+		return nil
+	})
+	seq := prefix_trees.SliceSeq(keys, values)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := byte_shard_trie.BuildFromSorted(seq); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package byte_shard_trie
+
+import "github.com/strider2038/algos/prefix_trees/internal/cursor"
+
+func (array *Array[V]) cursor() *cursor.Cursor[*arrayNode[V], V] {
+	return cursor.New[*arrayNode[V], V](&array.root)
+}
+
+// Методы перехода между узлами для курсора (интерфейс cursor.Node). Дочерние узлы
+// всех шардов узла нумеруются сквозным порядковым номером: сначала узлы шарда 0,
+// затем шарда 1 и т.д.
+
+func (node *arrayNode[V]) HasValue() bool {
+	return node.value != nil
+}
+
+func (node *arrayNode[V]) Value() V {
+	return *node.value
+}
+
+// ChildCount возвращает количество дочерних узлов во всех шардах.
+func (node *arrayNode[V]) ChildCount() int {
+	return len(node.children[0]) + len(node.children[1]) + len(node.children[2]) + len(node.children[3])
+}
+
+// Child возвращает дочерний узел по сквозному номеру.
+func (node *arrayNode[V]) Child(i int) *arrayNode[V] {
+	for hi := range node.children {
+		if i < len(node.children[hi]) {
+			return &node.children[hi][i]
+		}
+		i -= len(node.children[hi])
+	}
+
+	panic("child index out of range")
+}
+
+func (node *arrayNode[V]) AppendLabel(key []byte) []byte {
+	return append(key, node.k)
+}
+
+// SeekChild находит сквозной номер первого дочернего узла с байтом, большим
+// или равным первому байту ключа.
+func (node *arrayNode[V]) SeekChild(key []byte) (int, int, bool) {
+	hi, lo := splitKey(key[0])
+	i := node.bits[hi].getOneNumber(lo)
+	for shard := byte(0); shard < hi; shard++ {
+		i += len(node.children[shard])
+	}
+
+	return i, 1, node.bits[hi].isSet(lo)
+}
@@ -0,0 +1,42 @@
+package byte_shard_trie
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+func (array Array[V]) MarshalJSON() ([]byte, error) {
+	var data bytes.Buffer
+	if err := array.EncodeJSON(&data); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// UnmarshalJSON добавляет в дерево элементы объекта JSON. Существующие значения
+// с другими ключами сохраняются, как при декодировании в map.
+func (array *Array[V]) UnmarshalJSON(data []byte) error {
+	return array.DecodeJSON(bytes.NewReader(data))
+}
+
+// EncodeJSON записывает дерево в w объектом JSON в порядке возрастания ключей.
+// Элементы записываются по одному, поэтому документ целиком в памяти не строится.
+// Для двоичных ключей следует использовать способ записи prefix_trees.KeyEscaped
+// или prefix_trees.KeyBase64.
+func (array *Array[V]) EncodeJSON(w io.Writer, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Encode(w, array.Walk, options...)
+}
+
+// DecodeJSON читает объект JSON из r с помощью потокового декодера и добавляет
+// его элементы в дерево. Способ записи ключей должен совпадать с указанным
+// при кодировании. При ошибке в дереве остаются элементы, прочитанные до нее.
+func (array *Array[V]) DecodeJSON(r io.Reader, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Decode(r, func(key []byte, value V) error {
+		array.Put(key, value)
+		return nil
+	}, options...)
+}
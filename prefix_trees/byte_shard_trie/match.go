@@ -0,0 +1,75 @@
+package byte_shard_trie
+
+import (
+	"math/bits"
+
+	"github.com/strider2038/algos/prefix_trees/internal/glob"
+)
+
+// ErrInvalidPattern - ошибка разбора шаблона поиска.
+var ErrInvalidPattern = glob.ErrInvalidPattern
+
+// Match перебирает в порядке возрастания все значения, ключи которых соответствуют
+// шаблону pattern, и для каждого из них вызывает функцию f.
+//
+// Поддерживаемые элементы шаблона:
+//
+//	?       любой байт
+//	*       любая последовательность байт (в том числе пустая)
+//	[abc]   любой байт из перечисленных
+//	[a-z]   любой байт из диапазона
+//	[!abc]  любой байт, кроме перечисленных (также [^abc])
+//	\c      байт c без специального значения
+//
+// Шаблон преобразуется в недетерминированный автомат, состояния которого
+// отслеживаются одновременно при обходе дерева. Допустимые байты всех состояний
+// объединяются в маску, каждое 64-битное слово которой пересекается с маской
+// соответствующего сегмента дочерних узлов, поэтому ветви без совпадений
+// не посещаются.
+func (array *Array[V]) Match(pattern []byte, f func(key []byte, value V) error) error {
+	automaton, err := glob.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	m := matcher[V]{automaton: automaton, f: f}
+
+	return m.match(&array.root, 0)
+}
+
+type matcher[V any] struct {
+	automaton *glob.Automaton
+	f         func(key []byte, value V) error
+	// ключ текущего узла
+	key []byte
+}
+
+func (m *matcher[V]) match(node *arrayNode[V], depth int) error {
+	if node.value != nil && m.automaton.Accepts(depth) {
+		if err := m.f(m.key, *node.value); err != nil {
+			return err
+		}
+	}
+
+	// маска допустимых байт всех активных состояний
+	allowed := m.automaton.Allowed(depth)
+
+	// перебираем только дочерние узлы, байты которых допустимы шаблоном
+	for hi := range node.children {
+		for word := allowed[hi] & uint64(node.bits[hi]); word != 0; word &= word - 1 {
+			lo := byte(bits.TrailingZeros64(word))
+			k := byte(hi)<<6 | lo
+			if !m.automaton.Step(depth, k) {
+				continue
+			}
+
+			m.key = append(m.key[:depth], k)
+			child := &node.children[hi][node.bits[hi].getOneNumber(lo)]
+			if err := m.match(child, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
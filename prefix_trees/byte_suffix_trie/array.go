@@ -2,7 +2,8 @@ package byte_suffix_trie
 
 import (
 	"bytes"
-	"encoding/json"
+
+	"github.com/strider2038/algos/prefix_trees"
 )
 
 // Array префиксное дерево с оптимизацией памяти для хранения данных с произвольными
@@ -16,6 +17,8 @@ import (
 type Array[V any] struct {
 	root  arrayNode[V]
 	count int
+	// кодек значений для бинарной сериализации (см. SetValueCodec)
+	codec prefix_trees.ValueCodec[V]
 }
 
 func (array *Array[V]) Count() int {
@@ -29,25 +32,10 @@ func (array *Array[V]) Get(key []byte) V {
 }
 
 func (array *Array[V]) Find(key []byte) (V, bool) {
-	node := &array.root
 	var zero V
 
-	for i, k := range key {
-		// если индекс отсутствует в маске, то сверяем суффикс
-		if !node.bits.isSet(k) {
-			// если суффикс идентичен, то узел найден
-			if bytes.Equal(key[i:], node.suffix) {
-				break
-			}
-
-			// такого элемента нет в дереве
-			return zero, false
-		}
-		// по значению байта находим индекс следующего подузла дерева
-		node = node.child(k)
-	}
-
-	if node.present {
+	node, _ := array.root.find(key)
+	if node != nil && node.present {
 		return node.value, true
 	}
 
@@ -103,72 +91,110 @@ func (array *Array[V]) Put(key []byte, value V) {
 	node.value = value
 }
 
-// Delete удаляет значение из ассоциативного массива. Реализовано в виде
-// простой версии без освобождения памяти и уменьшения количества узлов.
+// Delete удаляет значение из ассоциативного массива. После удаления дерево
+// приводится к компактному виду: пустые узлы удаляются, а цепочки узлов
+// с единственным дочерним листом снова сворачиваются в суффикс. Таким образом
+// дерево принимает ту же форму, как если бы удаленный ключ никогда не добавлялся.
 func (array *Array[V]) Delete(key []byte) {
-	node := &array.root
-
-	for i, k := range key {
-		// если индекс отсутствует в маске, то такого элемента нет в дереве
-		if !node.bits.isSet(k) {
-			// если суффикс идентичен, то узел найден
-			if bytes.Equal(key[i:], node.suffix) {
-				break
-			}
-
-			// такого элемента нет в дереве
-			return
-		}
-
-		// по номеру символа находим индекс следующего подузла дерева
-		node = node.child(k)
-	}
-
-	// если значение установлено для узла, то уменьшаем счетчик количества элементов
-	if node.present {
-		array.count--
+	node, path := array.root.find(key)
+	// если значение не установлено для узла, то удалять нечего
+	if node == nil || !node.present {
+		return
 	}
 
-	// сбрасываем флаг присутствия
+	// сбрасываем флаг присутствия и уменьшаем счетчик количества элементов
+	var zero V
 	node.present = false
+	node.value = zero
+	array.count--
+
+	// поднимаемся от удаляемого узла к корню, приводя ветвь к компактному виду
+	for i := len(path) - 1; i >= 0; i-- {
+		parent := path[i]
+		if !node.present && len(node.children) == 0 {
+			// пустой лист удаляем из родительского узла
+			parent.remove(node.k)
+		} else {
+			node.mergeChild()
+		}
+		node = parent
+	}
 }
 
 // Walk перебирает дерево и для каждого существующего узла вызывает функцию f.
 func (array *Array[V]) Walk(f func(key []byte, value V) error) error {
-	return array.root.walk(nil, f)
+	return array.WalkPrefix(nil, f)
 }
 
-func (array Array[V]) MarshalJSON() ([]byte, error) {
-	var data bytes.Buffer
-	data.WriteRune('{')
-	i := 0
-
-	err := array.Walk(func(key []byte, value V) error {
-		if i > 0 {
-			data.WriteRune(',')
+// LongestPrefix находит самый длинный из хранящихся в дереве ключей, который является
+// префиксом ключа key. Возвращает длину найденного ключа, его значение и флаг наличия.
+// Для последнего узла дополнительно сверяется хранящийся в нем суффикс.
+// Сложность O(len(key)).
+func (array *Array[V]) LongestPrefix(key []byte) (int, V, bool) {
+	node := &array.root
+	matchedLen := -1
+	var value V
+
+	for i := 0; ; i++ {
+		// ключ узла совпадает с префиксом key[:i], если суффикс узла является
+		// продолжением этого префикса в ключе key
+		if node.present && bytes.HasPrefix(key[i:], node.suffix) {
+			matchedLen, value = i+len(node.suffix), node.value
 		}
+		// если индекс отсутствует в маске, то более длинных ключей нет
+		if i >= len(key) || !node.bits.isSet(key[i]) {
+			break
+		}
+		// по значению байта находим индекс следующего подузла дерева
+		node = node.child(key[i])
+	}
 
-		k, _ := json.Marshal(string(key))
-		data.Write(k)
+	if matchedLen < 0 {
+		return 0, value, false
+	}
 
-		data.WriteRune(':')
-		v, err := json.Marshal(value)
-		if err != nil {
-			return err
-		}
+	return matchedLen, value, true
+}
 
-		data.Write(v)
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+// Префикс может заканчиваться внутри суффикса, хранящегося в узле.
+func (array *Array[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	node := &array.root
+
+	for i, k := range prefix {
+		// если индекс отсутствует в маске, то сверяем суффикс
+		if !node.bits.isSet(k) {
+			// если остаток префикса является началом суффикса, то найден
+			// единственный подходящий ключ
+			if node.present && bytes.HasPrefix(node.suffix, prefix[i:]) {
+				key := append(append(make([]byte, 0, i+len(node.suffix)), prefix[:i]...), node.suffix...)
+
+				return f(key, node.value)
+			}
 
-		i++
+			// таких элементов нет в дереве
+			return nil
+		}
+		// по значению байта находим индекс следующего подузла дерева
+		node = node.child(k)
+	}
 
-		return nil
-	})
-	if err != nil {
-		return nil, err
+	// копируем префикс, чтобы не изменять массив вызывающей стороны при переборе
+	key := append(make([]byte, 0, len(prefix)), prefix...)
+	if node.present {
+		if err := f(append(key, node.suffix...), node.value); err != nil {
+			return err
+		}
 	}
-	data.WriteRune('}')
 
-	return data.Bytes(), nil
+	return node.walk(key, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (array *Array[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(array.WalkPrefix, prefix, limit)
 }
 
 type arrayNode[V any] struct {
@@ -193,6 +219,35 @@ func (node *arrayNode[V]) child(k byte) *arrayNode[V] {
 	return &node.children[childIndex]
 }
 
+// find - находит узел по ключу и возвращает его вместе с цепочкой родительских узлов
+// от корня. Если узел не найден, то возвращается nil.
+func (node *arrayNode[V]) find(key []byte) (*arrayNode[V], []*arrayNode[V]) {
+	var path []*arrayNode[V]
+
+	for i, k := range key {
+		// если индекс отсутствует в маске, то сверяем суффикс
+		if !node.bits.isSet(k) {
+			// если суффикс идентичен, то узел найден
+			if bytes.Equal(key[i:], node.suffix) {
+				return node, path
+			}
+
+			// такого элемента нет в дереве
+			return nil, nil
+		}
+		path = append(path, node)
+		// по значению байта находим индекс следующего подузла дерева
+		node = node.child(k)
+	}
+
+	// ключ является префиксом суффикса, хранящегося в узле
+	if len(node.suffix) > 0 {
+		return nil, nil
+	}
+
+	return node, path
+}
+
 func (node *arrayNode[V]) insert(k byte, suffix []byte) *arrayNode[V] {
 	// если не найден, то устанавливаем бит
 	node.bits.set(k)
@@ -217,6 +272,54 @@ func (node *arrayNode[V]) insertChildAt(index int, k byte, suffix []byte) {
 	node.children[index] = n
 }
 
+// remove - удаляет дочерний узел по значению байта
+func (node *arrayNode[V]) remove(k byte) {
+	node.removeChildAt(node.bits.getOneNumber(k))
+	node.bits.unset(k)
+}
+
+func (node *arrayNode[V]) removeChildAt(index int) {
+	n := len(node.children) - 1
+	if n == 0 {
+		// освобождаем массив целиком
+		node.children = nil
+		return
+	}
+
+	// сдвигаем элементы > index влево и очищаем освободившийся последний элемент,
+	// чтобы не удерживать ссылки на удаленные узлы
+	copy(node.children[index:], node.children[index+1:])
+	node.children[n] = arrayNode[V]{}
+	node.children = node.children[:n]
+
+	// если массив заполнен меньше чем наполовину, то перевыделяем память
+	// под точное количество элементов
+	if cap(node.children) >= 2*n {
+		children := make([]arrayNode[V], n)
+		copy(children, node.children)
+		node.children = children
+	}
+}
+
+// mergeChild - сворачивает единственный дочерний лист в суффикс текущего узла,
+// если текущий узел не содержит значения. Операция обратная forkSuffix.
+func (node *arrayNode[V]) mergeChild() {
+	if node.present || len(node.children) != 1 || len(node.children[0].children) > 0 {
+		return
+	}
+
+	child := node.children[0]
+	suffix := make([]byte, 0, len(child.suffix)+1)
+	suffix = append(suffix, child.k)
+	suffix = append(suffix, child.suffix...)
+
+	node.suffix = suffix
+	node.present = child.present
+	node.value = child.value
+	node.bits = bitIndex{}
+	node.children = nil
+}
+
 // splitBranch - разделяет текущую цепочку на основе суффикса и части ключа
 func (node *arrayNode[V]) splitBranch(key []byte, offset int) (*arrayNode[V], int) {
 	currentNode := node
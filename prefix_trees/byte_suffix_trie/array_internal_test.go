@@ -0,0 +1,142 @@
+package byte_suffix_trie
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArray_Delete_RestoresCompactShape(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []string
+		delete []string
+	}{
+		{
+			name:   "sibling with common prefix",
+			keys:   []string{"abcd", "abxy"},
+			delete: []string{"abxy"},
+		},
+		{
+			name:   "shorter key",
+			keys:   []string{"abc", "ab"},
+			delete: []string{"ab"},
+		},
+		{
+			name:   "longer key",
+			keys:   []string{"ab", "abc"},
+			delete: []string{"abc"},
+		},
+		{
+			name:   "branch with many keys",
+			keys:   []string{"car", "cart", "cat", "dog", "do"},
+			delete: []string{"cart", "cat", "do"},
+		},
+		{
+			name:   "all keys",
+			keys:   []string{"car", "cart", "cat"},
+			delete: []string{"cart", "car", "cat"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := build(test.keys, nil)
+			for _, key := range test.delete {
+				items.Delete([]byte(key))
+			}
+
+			assert.Equal(t, dump(build(test.keys, test.delete)), dump(items))
+		})
+	}
+}
+
+func TestArray_Delete_RandomStrings(t *testing.T) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		b := make([]byte, rand.Intn(6)+1)
+		for j := range b {
+			b[j] = "abc"[rand.Intn(3)]
+		}
+		keys[i] = string(b)
+	}
+	deleted := keys[:len(keys)/2]
+
+	items := build(keys, nil)
+	for _, key := range deleted {
+		items.Delete([]byte(key))
+	}
+
+	assert.Equal(t, dump(build(keys, deleted)), dump(items))
+}
+
+// build создает дерево из ключей keys, пропуская ключи из списка skip.
+func build(keys, skip []string) *Array[int] {
+	skipped := make(map[string]bool, len(skip))
+	for _, key := range skip {
+		skipped[key] = true
+	}
+
+	items := &Array[int]{}
+	for _, key := range keys {
+		if !skipped[key] {
+			items.Put([]byte(key), len(key))
+		}
+	}
+
+	return items
+}
+
+// dump возвращает текстовое представление структуры дерева.
+func dump(array *Array[int]) string {
+	var s strings.Builder
+	var f func(node *arrayNode[int], depth int)
+	f = func(node *arrayNode[int], depth int) {
+		s.WriteString(strings.Repeat(" ", depth))
+		s.WriteString(fmt.Sprintf("%q %q", node.k, node.suffix))
+		if node.present {
+			s.WriteString(fmt.Sprintf(" = %d", node.value))
+		}
+		s.WriteString("\n")
+		for i := range node.children {
+			f(&node.children[i], depth+1)
+		}
+	}
+	f(&array.root, 0)
+
+	return s.String()
+}
+
+func TestBuildFromSorted_CompactShape(t *testing.T) {
+	keys := []string{""}
+	for i := 0; i < 1000; i++ {
+		b := make([]byte, rand.Intn(8))
+		for j := range b {
+			b[j] = "abc"[rand.Intn(3)]
+		}
+		keys = append(keys, string(b))
+	}
+	items := build(keys, nil)
+
+	built, err := BuildFromSorted(items.Walk)
+
+	assert.NoError(t, err)
+	assert.Equal(t, dump(items), dump(built))
+	assertExactChildren(t, &built.root)
+}
+
+// assertExactChildren проверяет, что массивы дочерних узлов выделены под точное
+// количество элементов и соответствуют маскам.
+func assertExactChildren(t *testing.T, node *arrayNode[int]) {
+	t.Helper()
+
+	assert.Equal(t, len(node.children), cap(node.children))
+	for i := range node.children {
+		child := &node.children[i]
+		assert.True(t, node.bits.isSet(child.k))
+		assert.Equal(t, i, node.bits.getOneNumber(child.k))
+		assertExactChildren(t, child)
+	}
+}
@@ -0,0 +1,184 @@
+package byte_suffix_trie
+
+import (
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/binfmt"
+)
+
+// maskThreshold - количество дочерних узлов, начиная с которого вместо списка
+// их байт записывается битовая маска узла (32 байта).
+const maskThreshold = 32
+
+// флаги узла в бинарном формате
+const (
+	// узел содержит значение
+	flagValue = 1 << iota
+	// узел содержит суффикс ключа
+	flagSuffix
+)
+
+// SetValueCodec устанавливает кодек значений для бинарной сериализации.
+// По умолчанию используется prefix_trees.JSONCodec.
+func (array *Array[V]) SetValueCodec(codec prefix_trees.ValueCodec[V]) {
+	array.codec = codec
+}
+
+// MarshalBinary сохраняет дерево в компактном бинарном формате. Узлы записываются
+// в порядке обхода в глубину: флаги узла, значение, суффикс ключа, количество
+// дочерних узлов и их байты (или битовая маска для узлов с большим количеством
+// дочерних узлов). Данные содержат заголовок с версией формата и контрольную сумму.
+func (array *Array[V]) MarshalBinary() ([]byte, error) {
+	e := binfmt.NewEncoder(binfmt.KindByteSuffixTrie)
+	e.Uvarint(uint64(array.count))
+	if err := array.root.encode(e, array.valueCodec()); err != nil {
+		return nil, err
+	}
+
+	return e.Finish(), nil
+}
+
+// UnmarshalBinary восстанавливает дерево из данных, сохраненных методом MarshalBinary,
+// заменяя текущее содержимое. При ошибке дерево не изменяется.
+func (array *Array[V]) UnmarshalBinary(data []byte) error {
+	d, err := binfmt.NewDecoder(data, binfmt.KindByteSuffixTrie)
+	if err != nil {
+		return err
+	}
+
+	count := d.Uvarint()
+	var root arrayNode[V]
+	if root.decode(d, array.valueCodec()) != count {
+		d.Fail("values count mismatch")
+	}
+	if len(root.suffix) > 0 {
+		d.Fail("root node with suffix")
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+
+	array.root = root
+	array.count = int(count)
+
+	return nil
+}
+
+func (array *Array[V]) valueCodec() prefix_trees.ValueCodec[V] {
+	if array.codec == nil {
+		return prefix_trees.JSONCodec[V]{}
+	}
+
+	return array.codec
+}
+
+func (node *arrayNode[V]) encode(e *binfmt.Encoder, codec prefix_trees.ValueCodec[V]) error {
+	flags := byte(0)
+	if node.present {
+		flags |= flagValue
+	}
+	if len(node.suffix) > 0 {
+		flags |= flagSuffix
+	}
+	e.Byte(flags)
+
+	if node.present {
+		if err := binfmt.WriteValue(e, codec, node.value); err != nil {
+			return err
+		}
+	}
+	if len(node.suffix) > 0 {
+		e.Bytes(node.suffix)
+	}
+
+	e.Uvarint(uint64(len(node.children)))
+	if len(node.children) >= maskThreshold {
+		for _, word := range node.bits {
+			e.Uint64(word)
+		}
+	} else {
+		for i := range node.children {
+			e.Byte(node.children[i].k)
+		}
+	}
+
+	for i := range node.children {
+		if err := node.children[i].encode(e, codec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decode читает узел и его поддерево. Возвращает количество прочитанных значений.
+func (node *arrayNode[V]) decode(d *binfmt.Decoder, codec prefix_trees.ValueCodec[V]) uint64 {
+	count := uint64(0)
+
+	flags := d.Byte()
+	if flags&^(flagValue|flagSuffix) != 0 {
+		d.Fail("invalid node flags")
+	}
+	if flags&flagValue != 0 {
+		node.value = binfmt.ReadValue(d, codec)
+		node.present = true
+		count++
+	}
+	if flags&flagSuffix != 0 {
+		// данные не должны использоваться после возврата из UnmarshalBinary,
+		// поэтому суффикс копируется
+		node.suffix = append([]byte(nil), d.Bytes()...)
+		if len(node.suffix) == 0 || !node.present {
+			d.Fail("invalid suffix node")
+		}
+	}
+
+	n := d.Uvarint()
+	if n > 256 || n > 0 && len(node.suffix) > 0 {
+		d.Fail("invalid children count")
+	}
+	if d.Err() != nil || n == 0 {
+		return count
+	}
+
+	node.children = make([]arrayNode[V], n)
+	if n >= maskThreshold {
+		for i := range node.bits {
+			node.bits[i] = d.Uint64()
+		}
+		if node.bits.count() != int(n) {
+			d.Fail("invalid children mask")
+			return 0
+		}
+		// байты дочерних узлов восстанавливаются по маске в порядке возрастания
+		i := 0
+		for k := 0; k < 256; k++ {
+			if node.bits.isSet(byte(k)) {
+				node.children[i].k = byte(k)
+				i++
+			}
+		}
+	} else {
+		keys := d.Raw(int(n))
+		for i, k := range keys {
+			if i > 0 && k <= keys[i-1] {
+				d.Fail("unordered children")
+				return 0
+			}
+			node.bits.set(k)
+			node.children[i].k = k
+		}
+	}
+
+	for i := range node.children {
+		if d.Err() != nil {
+			return 0
+		}
+		count += node.children[i].decode(d, codec)
+		// Delete удаляет пустые листья, поэтому их нет в корректных данных
+		if child := &node.children[i]; !child.present && len(child.children) == 0 {
+			d.Fail("empty leaf node")
+		}
+	}
+
+	return count
+}
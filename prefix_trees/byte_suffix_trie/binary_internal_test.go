@@ -0,0 +1,105 @@
+package byte_suffix_trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/binfmt"
+)
+
+func TestArray_MarshalBinary_KeepsSuffixes(t *testing.T) {
+	items := build([]string{"", "a", "abcdef", "abxyz", "b", "bcdefgh", "c"}, nil)
+
+	data, err := items.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &Array[int]{}
+	err = restored.UnmarshalBinary(data)
+	// суффиксы копируются и не ссылаются на данные
+	for i := range data {
+		data[i] = 0
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, dump(items), dump(restored))
+}
+
+func TestArray_UnmarshalBinary_InvalidSuffix(t *testing.T) {
+	tests := []struct {
+		name   string
+		encode func(e *binfmt.Encoder)
+	}{
+		{
+			name: "root with suffix",
+			encode: func(e *binfmt.Encoder) {
+				e.Uvarint(1)
+				e.Byte(flagValue | flagSuffix)
+				e.Uvarint(2)
+				e.Raw([]byte("42"))
+				e.Bytes([]byte("ab"))
+				e.Uvarint(0)
+			},
+		},
+		{
+			name: "suffix without value",
+			encode: func(e *binfmt.Encoder) {
+				e.Uvarint(0)
+				e.Byte(0)
+				e.Uvarint(1)
+				e.Byte('a')
+				e.Byte(flagSuffix)
+				e.Bytes([]byte("bc"))
+				e.Uvarint(0)
+			},
+		},
+		{
+			name: "suffix with children",
+			encode: func(e *binfmt.Encoder) {
+				e.Uvarint(2)
+				e.Byte(0)
+				e.Uvarint(1)
+				e.Byte('a')
+				e.Byte(flagValue | flagSuffix)
+				e.Uvarint(1)
+				e.Raw([]byte("1"))
+				e.Bytes([]byte("bc"))
+				e.Uvarint(1)
+				e.Byte('d')
+				e.Byte(flagValue)
+				e.Uvarint(1)
+				e.Raw([]byte("2"))
+				e.Uvarint(0)
+			},
+		},
+		{
+			name: "empty leaf",
+			encode: func(e *binfmt.Encoder) {
+				e.Uvarint(1)
+				e.Byte(0)
+				e.Uvarint(2)
+				e.Raw([]byte("ab"))
+				e.Byte(flagValue | flagSuffix)
+				e.Uvarint(1)
+				e.Raw([]byte("1"))
+				e.Bytes([]byte("cd"))
+				e.Uvarint(0)
+				e.Byte(0)
+				e.Uvarint(0)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := binfmt.NewEncoder(binfmt.KindByteSuffixTrie)
+			test.encode(e)
+			items := build([]string{"alpha"}, nil)
+
+			err := items.UnmarshalBinary(e.Finish())
+
+			assert.ErrorIs(t, err, prefix_trees.ErrCorruptedData)
+			assert.Equal(t, 5, items.Get([]byte("alpha")))
+		})
+	}
+}
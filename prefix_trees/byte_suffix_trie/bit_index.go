@@ -10,6 +10,11 @@ func (b *bitIndex) set(n byte) {
 	b[hi] = b[hi] | (1 << lo)
 }
 
+func (b *bitIndex) unset(n byte) {
+	hi, lo := b.splitN(n)
+	b[hi] = b[hi] & ^(1 << lo)
+}
+
 func (b *bitIndex) isSet(n byte) bool {
 	hi, lo := b.splitN(n)
 
@@ -44,3 +49,13 @@ func (b *bitIndex) getOneNumber(n byte) int {
 func (b *bitIndex) splitN(n byte) (byte, byte) {
 	return n >> 6, n & 0x3F
 }
+
+// count возвращает количество установленных битов.
+func (b *bitIndex) count() int {
+	n := 0
+	for _, word := range b {
+		n += bits.OnesCount64(word)
+	}
+
+	return n
+}
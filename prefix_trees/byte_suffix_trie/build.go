@@ -0,0 +1,181 @@
+package byte_suffix_trie
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// ErrUnsortedKeys - ключи для построения дерева не упорядочены по возрастанию
+// или повторяются.
+var ErrUnsortedKeys = errors.New("keys are not sorted")
+
+// suffixChunkSize - размер блока, в который копируются суффиксы ключей при построении.
+const suffixChunkSize = 64 * 1024
+
+// BuildFromSorted строит дерево из последовательности ключей, упорядоченных
+// по возрастанию байт (например, из метода Walk другого дерева или prefix_trees.SliceSeq).
+// Если ключи не упорядочены или повторяются, возвращается ошибка ErrUnsortedKeys.
+//
+// Дерево имеет ту же компактную форму, что и при заполнении методом Put, но дочерние
+// узлы только добавляются в конец и не сдвигаются, массив дочерних узлов каждого узла
+// выделяется один раз под точное количество элементов, а суффиксы ключей копируются
+// в общие блоки памяти.
+func BuildFromSorted[V any](seq prefix_trees.Seq[[]byte, V]) (*Array[V], error) {
+	b := sortedBuilder[V]{spine: make([]spineNode[V], 1, 32)}
+	if err := seq(b.add); err != nil {
+		return nil, err
+	}
+
+	return &Array[V]{root: b.finish(), count: b.count}, nil
+}
+
+// sortedBuilder строит дерево из упорядоченных ключей по правой границе дерева:
+// открытыми остаются только узлы пути последнего ключа, все узлы левее уже
+// не изменятся. Узел закрывается, когда следующий ключ отходит от его пути,
+// и добавляется в конец дочерних узлов родителя.
+//
+// Последний ключ хранится листом с суффиксом под самым глубоким открытым узлом.
+// Лист раскрывается в цепочку открытых узлов, только если следующий ключ имеет
+// с ним более длинный общий префикс, поэтому открытые узлы (кроме корня) всегда
+// содержат не менее двух ключей, как и узлы без суффикса в дереве после Put.
+type sortedBuilder[V any] struct {
+	// открытые узлы пути последнего ключа: spine[d] - узел на глубине d
+	spine []spineNode[V]
+	// лист последнего ключа
+	leaf    arrayNode[V]
+	hasLeaf bool
+	// блок для копирования суффиксов
+	chunk    []byte
+	previous []byte
+	count    int
+}
+
+type spineNode[V any] struct {
+	node arrayNode[V]
+	// закрытые дочерние узлы (буфер используется повторно для узлов той же глубины)
+	children []arrayNode[V]
+}
+
+func (b *sortedBuilder[V]) add(key []byte, value V) error {
+	if b.count > 0 && string(key) <= string(b.previous) {
+		return fmt.Errorf("%w: %q after %q", ErrUnsortedKeys, key, b.previous)
+	}
+
+	common := 0
+	for common < len(key) && common < len(b.previous) && key[common] == b.previous[common] {
+		common++
+	}
+	if depth := len(b.spine) - 1; b.hasLeaf && common > depth {
+		b.expandLeaf(depth, common)
+	}
+	if b.hasLeaf {
+		b.spine[len(b.spine)-1].append(b.leaf)
+		b.hasLeaf = false
+	}
+	b.closeTo(common)
+
+	if common == len(key) {
+		// только пустой первый ключ хранится в корне
+		b.spine[0].node.present = true
+		b.spine[0].node.value = value
+	} else {
+		b.leaf = arrayNode[V]{k: key[common], suffix: b.copySuffix(key[common+1:]), present: true, value: value}
+		b.hasLeaf = true
+	}
+
+	b.previous = append(b.previous[:0], key...)
+	b.count++
+
+	return nil
+}
+
+// finish закрывает все узлы и возвращает корень дерева.
+func (b *sortedBuilder[V]) finish() arrayNode[V] {
+	if b.hasLeaf {
+		b.spine[len(b.spine)-1].append(b.leaf)
+		b.hasLeaf = false
+	}
+	b.closeTo(0)
+
+	return b.spine[0].close()
+}
+
+// expandLeaf раскрывает лист последнего ключа, находящийся под открытым узлом
+// глубины depth, в цепочку открытых узлов до глубины common.
+func (b *sortedBuilder[V]) expandLeaf(depth, common int) {
+	leaf := b.leaf
+	b.hasLeaf = false
+	for _, k := range b.previous[depth:common] {
+		b.open(k)
+	}
+
+	if len(b.previous) == common {
+		top := &b.spine[common].node
+		top.present = true
+		top.value = leaf.value
+		return
+	}
+
+	// суффикс листа - previous[depth+1:], остаток после раскрытия - previous[common+1:]
+	b.leaf = arrayNode[V]{k: b.previous[common], suffix: leaf.suffix[common-depth:], present: true, value: leaf.value}
+	b.hasLeaf = true
+}
+
+// open открывает узел с байтом k на следующей глубине.
+func (b *sortedBuilder[V]) open(k byte) {
+	if len(b.spine) < cap(b.spine) {
+		// буфер дочерних узлов ранее закрытого узла этой глубины сохраняется
+		b.spine = b.spine[:len(b.spine)+1]
+	} else {
+		b.spine = append(b.spine, spineNode[V]{})
+	}
+	top := &b.spine[len(b.spine)-1]
+	top.node = arrayNode[V]{k: k}
+	top.children = top.children[:0]
+}
+
+// closeTo закрывает узлы глубже depth.
+func (b *sortedBuilder[V]) closeTo(depth int) {
+	for last := len(b.spine) - 1; last > depth; last-- {
+		node := b.spine[last].close()
+		b.spine = b.spine[:last]
+		b.spine[last-1].append(node)
+	}
+}
+
+// copySuffix копирует суффикс в текущий блок памяти.
+func (b *sortedBuilder[V]) copySuffix(suffix []byte) []byte {
+	if len(suffix) == 0 {
+		return nil
+	}
+	if len(suffix) > cap(b.chunk)-len(b.chunk) {
+		size := suffixChunkSize
+		if len(suffix) > size {
+			size = len(suffix)
+		}
+		b.chunk = make([]byte, 0, size)
+	}
+
+	start := len(b.chunk)
+	b.chunk = append(b.chunk, suffix...)
+
+	return b.chunk[start:len(b.chunk):len(b.chunk)]
+}
+
+func (s *spineNode[V]) append(child arrayNode[V]) {
+	s.node.bits.set(child.k)
+	s.children = append(s.children, child)
+}
+
+// close возвращает узел с массивом дочерних узлов точного размера.
+func (s *spineNode[V]) close() arrayNode[V] {
+	node := s.node
+	if len(s.children) > 0 {
+		node.children = make([]arrayNode[V], len(s.children))
+		copy(node.children, s.children)
+	}
+
+	return node
+}
@@ -0,0 +1,31 @@
+package byte_suffix_trie
+
+// Min возвращает наименьший ключ дерева и его значение.
+func (array *Array[V]) Min() ([]byte, V, bool) {
+	return array.cursor().Min()
+}
+
+// Max возвращает наибольший ключ дерева и его значение.
+func (array *Array[V]) Max() ([]byte, V, bool) {
+	return array.cursor().Max()
+}
+
+// Floor возвращает наибольший ключ, меньший или равный key, и его значение.
+func (array *Array[V]) Floor(key []byte) ([]byte, V, bool) {
+	return array.cursor().Floor(key)
+}
+
+// Ceiling возвращает наименьший ключ, больший или равный key, и его значение.
+func (array *Array[V]) Ceiling(key []byte) ([]byte, V, bool) {
+	return array.cursor().Ceiling(key)
+}
+
+// Predecessor возвращает наибольший ключ, строго меньший key, и его значение.
+func (array *Array[V]) Predecessor(key []byte) ([]byte, V, bool) {
+	return array.cursor().Predecessor(key)
+}
+
+// Successor возвращает наименьший ключ, строго больший key, и его значение.
+func (array *Array[V]) Successor(key []byte) ([]byte, V, bool) {
+	return array.cursor().Successor(key)
+}
@@ -1,9 +1,6 @@
 package byte_trie
 
-import (
-	"bytes"
-	"encoding/json"
-)
+import "github.com/strider2038/algos/prefix_trees"
 
 // Array префиксное дерево с оптимизацией памяти для хранения данных с произвольными
 // ключами в виде слайса байт.
@@ -16,6 +13,10 @@ import (
 type Array[V any] struct {
 	root  arrayNode[V]
 	count int
+	// режим подсчета количества значений в поддеревьях узлов (см. NewCountedArray)
+	counted bool
+	// кодек значений для бинарной сериализации (см. SetValueCodec)
+	codec prefix_trees.ValueCodec[V]
 }
 
 func (array *Array[V]) Count() int {
@@ -72,76 +73,133 @@ func (array *Array[V]) Put(key []byte, value V) {
 	// увеличиваем счетчик количества элементов
 	if node.value == nil {
 		array.count++
+		if array.counted {
+			array.root.addSize(key, 1)
+		}
 	}
 
 	node.value = &value
 }
 
-// Delete удаляет значение из ассоциативного массива. Реализовано в виде
-// простой версии без освобождения памяти и уменьшения количества узлов.
+// Delete удаляет значение из ассоциативного массива. Узлы, которые после удаления
+// не содержат ни значения, ни дочерних узлов, удаляются из дерева вместе с битами
+// в масках родительских узлов.
 func (array *Array[V]) Delete(key []byte) {
 	node := &array.root
+	// цепочка родительских узлов от корня до удаляемого узла
+	path := make([]*arrayNode[V], 0, len(key))
 
 	for _, k := range key {
 		// если индекс отсутствует в маске, то такого элемента нет в дереве
 		if !node.bits.isSet(k) {
 			return
 		}
+		path = append(path, node)
 		// по номеру символа находим индекс следующего подузла дерева
 		i := node.bits.getOneNumber(k)
 		node = &node.children[i]
 	}
 
-	// если значение установлено для узла, то уменьшаем счетчик количества элементов
-	if node.value != nil {
-		array.count--
+	// если значение не установлено для узла, то удалять нечего
+	if node.value == nil {
+		return
 	}
 
-	// удаляем ссылку на значение
+	// удаляем ссылку на значение и уменьшаем счетчик количества элементов
 	node.value = nil
+	array.count--
+	if array.counted {
+		array.root.addSize(key, -1)
+	}
+
+	// поднимаемся от удаляемого узла к корню, отсекая пустые листья
+	for i := len(path) - 1; i >= 0; i-- {
+		if node.value != nil || len(node.children) > 0 {
+			break
+		}
+		parent := path[i]
+		k := key[i]
+		parent.removeChildAt(parent.bits.getOneNumber(k))
+		parent.bits.unset(k)
+		node = parent
+	}
 }
 
 // Walk перебирает дерево и для каждого существующего узла вызывает функцию f.
 func (array *Array[V]) Walk(f func(key []byte, value V) error) error {
-	return array.root.walk(nil, f)
+	return array.WalkPrefix(nil, f)
 }
 
-func (array Array[V]) MarshalJSON() ([]byte, error) {
-	var data bytes.Buffer
-	data.WriteRune('{')
-	i := 0
+// LongestPrefix находит самый длинный из хранящихся в дереве ключей, который является
+// префиксом ключа key. Возвращает длину найденного ключа, его значение и флаг наличия.
+// Сложность O(len(key)).
+func (array *Array[V]) LongestPrefix(key []byte) (int, V, bool) {
+	node := &array.root
+	matchedLen := -1
+	var value V
+
+	if node.value != nil {
+		matchedLen, value = 0, *node.value
+	}
 
-	err := array.Walk(func(key []byte, value V) error {
-		if i > 0 {
-			data.WriteRune(',')
+	for i, k := range key {
+		// если индекс отсутствует в маске, то более длинных ключей нет
+		if !node.bits.isSet(k) {
+			break
+		}
+		// по номеру символа находим индекс следующего подузла дерева
+		node = &node.children[node.bits.getOneNumber(k)]
+		if node.value != nil {
+			matchedLen, value = i+1, *node.value
 		}
+	}
 
-		k, _ := json.Marshal(string(key))
-		data.Write(k)
+	if matchedLen < 0 {
+		return 0, value, false
+	}
 
-		data.WriteRune(':')
-		v, err := json.Marshal(value)
-		if err != nil {
-			return err
-		}
+	return matchedLen, value, true
+}
 
-		data.Write(v)
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (array *Array[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	node := &array.root
 
-		i++
+	for _, k := range prefix {
+		// если индекс отсутствует в маске, то таких элементов нет в дереве
+		if !node.bits.isSet(k) {
+			return nil
+		}
+		// по номеру символа находим индекс следующего подузла дерева
+		i := node.bits.getOneNumber(k)
+		node = &node.children[i]
+	}
 
-		return nil
-	})
-	if err != nil {
-		return nil, err
+	// копируем префикс, чтобы не изменять массив вызывающей стороны при переборе
+	key := append(make([]byte, 0, len(prefix)), prefix...)
+	if node.value != nil {
+		if err := f(key, *node.value); err != nil {
+			return err
+		}
 	}
-	data.WriteRune('}')
 
-	return data.Bytes(), nil
+	return node.walk(key, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (array *Array[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(array.WalkPrefix, prefix, limit)
 }
 
 type arrayNode[V any] struct {
 	// Символ
 	k byte
+	// Количество значений в поддереве узла (включая сам узел). Поддерживается
+	// только в режиме подсчета, размещается в выравнивании после поля k и
+	// не увеличивает размер узла.
+	size uint32
 	// Битовая маска для индексации массива нижележащих узлов
 	bits bitIndex
 	// Массив нижележащих узлов переменной длины (на основе слайса)
@@ -163,6 +221,29 @@ func (node *arrayNode[V]) insertChildAt(index int, k byte) {
 	node.children[index] = n
 }
 
+func (node *arrayNode[V]) removeChildAt(index int) {
+	n := len(node.children) - 1
+	if n == 0 {
+		// освобождаем массив целиком
+		node.children = nil
+		return
+	}
+
+	// сдвигаем элементы > index влево и очищаем освободившийся последний элемент,
+	// чтобы не удерживать ссылки на удаленные узлы
+	copy(node.children[index:], node.children[index+1:])
+	node.children[n] = arrayNode[V]{}
+	node.children = node.children[:n]
+
+	// если массив заполнен меньше чем наполовину, то перевыделяем память
+	// под точное количество элементов
+	if cap(node.children) >= 2*n {
+		children := make([]arrayNode[V], n)
+		copy(children, node.children)
+		node.children = children
+	}
+}
+
 func (node *arrayNode[V]) walk(key []byte, f func(key []byte, value V) error) error {
 	for _, child := range node.children {
 		k := append(key, child.k)
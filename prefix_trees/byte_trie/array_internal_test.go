@@ -0,0 +1,87 @@
+package byte_trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArray_Delete_PrunesNodes(t *testing.T) {
+	items := Array[int]{}
+	items.Put([]byte("car"), 1)
+	items.Put([]byte("cart"), 2)
+	items.Put([]byte("cat"), 3)
+	items.Put([]byte("dog"), 4)
+
+	items.Delete([]byte("cart"))
+
+	car := &items.root.children[0].children[0].children[1]
+	assert.Nil(t, car.children)
+	assert.False(t, car.bits.isSet('t'))
+
+	items.Delete([]byte("cat"))
+	items.Delete([]byte("car"))
+
+	assert.Len(t, items.root.children, 1)
+	assert.False(t, items.root.bits.isSet('c'))
+	assert.True(t, items.root.bits.isSet('d'))
+	assert.Equal(t, 4, items.Get([]byte("dog")))
+
+	items.Delete([]byte("dog"))
+
+	assert.Nil(t, items.root.children)
+	assert.Equal(t, bitIndex{}, items.root.bits)
+}
+
+func TestArrayNode_RemoveChildAt_ShrinksCapacity(t *testing.T) {
+	node := arrayNode[int]{}
+	for k := byte(0); k < 16; k++ {
+		node.bits.set(k)
+		node.insertChildAt(int(k), k)
+	}
+
+	for k := byte(15); k >= 4; k-- {
+		node.removeChildAt(int(k))
+		node.bits.unset(k)
+	}
+
+	assert.Len(t, node.children, 4)
+	assert.LessOrEqual(t, cap(node.children), 8)
+	for i, child := range node.children {
+		assert.Equal(t, byte(i), child.k)
+	}
+}
+
+func TestBuildFromSorted_SameShapeAsPut(t *testing.T) {
+	items := &Array[int]{}
+	items.Put(nil, 0)
+	for i := 0; i < 1000; i++ {
+		b := make([]byte, rand.Intn(8))
+		for j := range b {
+			b[j] = "abc\x00\xff"[rand.Intn(5)]
+		}
+		items.Put(b, len(b))
+	}
+
+	built, err := BuildFromSorted(items.Walk)
+
+	assert.NoError(t, err)
+	assertSameShape(t, &items.root, &built.root)
+}
+
+// assertSameShape сравнивает узлы дерева, заполненного методом Put, и построенного
+// дерева, у которого массивы дочерних узлов должны быть выделены под точное количество элементов.
+func assertSameShape(t *testing.T, want, got *arrayNode[int]) {
+	t.Helper()
+
+	assert.Equal(t, want.k, got.k)
+	assert.Equal(t, want.bits, got.bits)
+	assert.Equal(t, want.value, got.value)
+	assert.Equal(t, len(got.children), cap(got.children))
+	if assert.Len(t, got.children, len(want.children)) {
+		for i := range want.children {
+			assertSameShape(t, &want.children[i], &got.children[i])
+		}
+	}
+}
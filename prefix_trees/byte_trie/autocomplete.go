@@ -0,0 +1,301 @@
+package byte_trie
+
+import (
+	"bytes"
+	"container/heap"
+	"math"
+)
+
+// Autocomplete префиксное дерево для автодополнения с ранжированием подсказок по весу.
+// Устройство узлов повторяет Array: дочерние узлы хранятся в массиве переменной длины
+// с индексацией по битовой маске. Дополнительно каждый узел хранит наибольший вес
+// значений в своем поддереве, поэтому метод TopK находит k самых тяжелых подсказок,
+// не перебирая поддерево префикса целиком.
+//
+// Вес значения вычисляется функцией, переданной в конструктор NewAutocomplete.
+type Autocomplete[V any] struct {
+	root   autocompleteNode[V]
+	count  int
+	weight func(value V) float64
+}
+
+// Completion - подсказка автодополнения.
+type Completion[V any] struct {
+	Key    []byte
+	Value  V
+	Weight float64
+}
+
+// NewAutocomplete создает дерево автодополнения с функцией вычисления веса значения.
+func NewAutocomplete[V any](weight func(value V) float64) *Autocomplete[V] {
+	return &Autocomplete[V]{
+		root:   autocompleteNode[V]{best: math.Inf(-1)},
+		weight: weight,
+	}
+}
+
+func (ac *Autocomplete[V]) Count() int {
+	return ac.count
+}
+
+func (ac *Autocomplete[V]) Get(key []byte) V {
+	v, _ := ac.Find(key)
+
+	return v
+}
+
+func (ac *Autocomplete[V]) Find(key []byte) (V, bool) {
+	node := &ac.root
+	var zero V
+
+	for _, k := range key {
+		// если индекс отсутствует в маске, то такого элемента нет в дереве
+		if !node.bits.isSet(k) {
+			return zero, false
+		}
+		// по номеру символа находим индекс следующего подузла дерева
+		node = &node.children[node.bits.getOneNumber(k)]
+	}
+
+	if node.value != nil {
+		return *node.value, true
+	}
+
+	return zero, false
+}
+
+// Put сохраняет значение по ключу и обновляет наибольшие веса в узлах на пути к нему.
+func (ac *Autocomplete[V]) Put(key []byte, value V) {
+	if ac.root.put(key, value, ac.weight(value)) {
+		ac.count++
+	}
+}
+
+// Delete удаляет значение по ключу, удаляет опустевшие узлы и обновляет
+// наибольшие веса в узлах на пути к нему.
+func (ac *Autocomplete[V]) Delete(key []byte) {
+	if ac.root.delete(key) {
+		ac.count--
+	}
+}
+
+// TopK возвращает не более k подсказок с наибольшим весом среди ключей, начинающихся
+// с префикса prefix. Подсказки упорядочены по убыванию веса, при равном весе -
+// по возрастанию ключа.
+//
+// Поиск выполняется по принципу "лучший - первым": в очереди с приоритетом хранятся
+// поддеревья с их наибольшим весом и отдельные значения. Поддерево раскрывается
+// только тогда, когда его наибольший вес не меньше веса уже найденных подсказок,
+// поэтому количество посещенных узлов зависит от k и длины ключей,
+// а не от размера поддерева.
+func (ac *Autocomplete[V]) TopK(prefix []byte, k int) []Completion[V] {
+	if k <= 0 {
+		return nil
+	}
+
+	node := &ac.root
+	for _, b := range prefix {
+		// если индекс отсутствует в маске, то таких элементов нет в дереве
+		if !node.bits.isSet(b) {
+			return nil
+		}
+		node = &node.children[node.bits.getOneNumber(b)]
+	}
+	if node.value == nil && len(node.children) == 0 {
+		return nil
+	}
+
+	completions := make([]Completion[V], 0, k)
+	queue := &completionQueue[V]{}
+	heap.Push(queue, completionItem[V]{
+		node:   node,
+		key:    append([]byte{}, prefix...),
+		weight: node.best,
+	})
+
+	for queue.Len() > 0 && len(completions) < k {
+		item := heap.Pop(queue).(completionItem[V])
+		if item.isValue {
+			completions = append(completions, Completion[V]{
+				Key:    item.key,
+				Value:  *item.node.value,
+				Weight: item.weight,
+			})
+			continue
+		}
+
+		// раскрываем поддерево: значение узла и дочерние поддеревья
+		// становятся отдельными элементами очереди
+		if item.node.value != nil {
+			heap.Push(queue, completionItem[V]{
+				node:    item.node,
+				key:     item.key,
+				weight:  item.node.weight,
+				isValue: true,
+			})
+		}
+		for i := range item.node.children {
+			child := &item.node.children[i]
+			key := make([]byte, len(item.key)+1)
+			copy(key, item.key)
+			key[len(item.key)] = child.k
+
+			heap.Push(queue, completionItem[V]{node: child, key: key, weight: child.best})
+		}
+	}
+
+	return completions
+}
+
+type autocompleteNode[V any] struct {
+	// Символ
+	k byte
+	// Битовая маска для индексации массива нижележащих узлов
+	bits bitIndex
+	// Массив нижележащих узлов переменной длины (на основе слайса)
+	children []autocompleteNode[V]
+	// Ссылка на значение ассоциативного массива
+	value *V
+	// Вес значения узла
+	weight float64
+	// Наибольший вес значений в поддереве узла (включая сам узел)
+	best float64
+}
+
+// put сохраняет значение в поддереве узла. Возвращает true, если значение добавлено
+// по новому ключу.
+func (node *autocompleteNode[V]) put(key []byte, value V, weight float64) bool {
+	added := false
+
+	if len(key) == 0 {
+		added = node.value == nil
+		node.value = &value
+		node.weight = weight
+	} else {
+		k := key[0]
+		// если не найден, то устанавливаем бит и расширяем массив
+		if !node.bits.isSet(k) {
+			node.bits.set(k)
+			node.insertChildAt(node.bits.getOneNumber(k), k)
+		}
+		added = node.children[node.bits.getOneNumber(k)].put(key[1:], value, weight)
+	}
+
+	node.updateBest()
+
+	return added
+}
+
+// delete удаляет значение из поддерева узла. Возвращает true, если значение было удалено.
+func (node *autocompleteNode[V]) delete(key []byte) bool {
+	if len(key) == 0 {
+		if node.value == nil {
+			return false
+		}
+		node.value = nil
+		node.updateBest()
+
+		return true
+	}
+
+	k := key[0]
+	// если индекс отсутствует в маске, то такого элемента нет в дереве
+	if !node.bits.isSet(k) {
+		return false
+	}
+
+	i := node.bits.getOneNumber(k)
+	child := &node.children[i]
+	if !child.delete(key[1:]) {
+		return false
+	}
+
+	// опустевший лист удаляем из дерева
+	if child.value == nil && len(child.children) == 0 {
+		copy(node.children[i:], node.children[i+1:])
+		node.children[len(node.children)-1] = autocompleteNode[V]{}
+		node.children = node.children[:len(node.children)-1]
+		if len(node.children) == 0 {
+			node.children = nil
+		}
+		node.bits.unset(k)
+	}
+	node.updateBest()
+
+	return true
+}
+
+// updateBest пересчитывает наибольший вес поддерева по значению узла
+// и наибольшим весам дочерних узлов.
+func (node *autocompleteNode[V]) updateBest() {
+	best := math.Inf(-1)
+	if node.value != nil {
+		best = node.weight
+	}
+	for i := range node.children {
+		if node.children[i].best > best {
+			best = node.children[i].best
+		}
+	}
+
+	node.best = best
+}
+
+func (node *autocompleteNode[V]) insertChildAt(index int, k byte) {
+	n := autocompleteNode[V]{k: k, best: math.Inf(-1)}
+	if len(node.children) == index {
+		// вставка в конец слайса (расширение массива)
+		node.children = append(node.children, n)
+		return
+	}
+
+	// вставка в середину слайса со смещением элементов > index вправо
+	node.children = append(node.children[:index+1], node.children[index:]...)
+	node.children[index] = n
+}
+
+// completionItem - элемент очереди поиска подсказок: поддерево узла
+// или отдельное значение узла.
+type completionItem[V any] struct {
+	node    *autocompleteNode[V]
+	key     []byte
+	weight  float64
+	isValue bool
+}
+
+// completionQueue - очередь с приоритетом по убыванию веса. При равном весе
+// значения идут раньше поддеревьев, а меньшие ключи - раньше больших.
+type completionQueue[V any] []completionItem[V]
+
+func (q completionQueue[V]) Len() int {
+	return len(q)
+}
+
+func (q completionQueue[V]) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight > q[j].weight
+	}
+	if cmp := bytes.Compare(q[i].key, q[j].key); cmp != 0 {
+		return cmp < 0
+	}
+
+	return q[i].isValue && !q[j].isValue
+}
+
+func (q completionQueue[V]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *completionQueue[V]) Push(x any) {
+	*q = append(*q, x.(completionItem[V]))
+}
+
+func (q *completionQueue[V]) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = completionItem[V]{}
+	*q = old[:n-1]
+
+	return item
+}
@@ -0,0 +1,120 @@
+package byte_trie_test
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestAutocomplete_TopK(t *testing.T) {
+	ac := byte_trie.NewAutocomplete(func(value int) float64 {
+		return float64(value)
+	})
+	m := map[string]int{}
+	for i := 0; i < 3000; i++ {
+		key := randomKey()
+		value := rand.Intn(100)
+		ac.Put([]byte(key), value)
+		m[key] = value
+	}
+	// удаляем и перезаписываем часть ключей, чтобы проверить пересчет весов
+	for key := range m {
+		switch len(key) % 4 {
+		case 0:
+			ac.Delete([]byte(key))
+			delete(m, key)
+		case 1:
+			ac.Put([]byte(key), 0)
+			m[key] = 0
+		}
+	}
+
+	assert.Equal(t, len(m), ac.Count())
+	for i := 0; i < 300; i++ {
+		prefix := randomKey()
+		if len(prefix) > 2 {
+			prefix = prefix[:2]
+		}
+		k := rand.Intn(20) + 1
+
+		assert.Equal(t, topK(m, prefix, k), completionKeys(ac.TopK([]byte(prefix), k)), "prefix %q, k %d", prefix, k)
+	}
+}
+
+func TestAutocomplete_TopK_Countries(t *testing.T) {
+	ac := byte_trie.NewAutocomplete(func(value int) float64 {
+		return float64(value)
+	})
+	for _, country := range fixtures.Countries {
+		ac.Put([]byte(country), len(country))
+	}
+
+	completions := ac.TopK([]byte("B"), 3)
+
+	assert.Equal(t, []string{
+		"British Indian Ocean Territory",
+		"Bosnia and Herzegovina",
+		"British Virgin Islands",
+	}, completionKeys(completions))
+	assert.Equal(t, 30.0, completions[0].Weight)
+	assert.Equal(t, 30, completions[0].Value)
+	assert.Empty(t, ac.TopK([]byte("X"), 3))
+	assert.Empty(t, ac.TopK([]byte("B"), 0))
+}
+
+func topK(m map[string]int, prefix string, k int) []string {
+	var keys []string
+	for key := range m {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > k {
+		keys = keys[:k]
+	}
+
+	return keys
+}
+
+func completionKeys(completions []byte_trie.Completion[int]) []string {
+	var keys []string
+	for _, c := range completions {
+		keys = append(keys, string(c.Key))
+	}
+
+	return keys
+}
+
+func BenchmarkAutocomplete_TopK(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	ac := byte_trie.NewAutocomplete(func(value int) float64 {
+		return float64(value)
+	})
+	for n, city := range cities {
+		ac.Put([]byte(city), n+1)
+	}
+
+	b.ResetTimer()
+
+	for _, prefix := range []string{"S", "San", "San Francisco"} {
+		b.Run(prefix, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				completions := ac.TopK([]byte(prefix), 10)
+				if len(completions) == 0 {
+					b.Fatal("elements not found")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package byte_trie
+
+import (
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/binfmt"
+)
+
+// maskThreshold - количество дочерних узлов, начиная с которого вместо списка
+// их байт записывается битовая маска узла (32 байта).
+const maskThreshold = 32
+
+// flagValue - флаг наличия значения узла в бинарном формате.
+const flagValue = 1
+
+// SetValueCodec устанавливает кодек значений для бинарной сериализации.
+// По умолчанию используется prefix_trees.JSONCodec.
+func (array *Array[V]) SetValueCodec(codec prefix_trees.ValueCodec[V]) {
+	array.codec = codec
+}
+
+// MarshalBinary сохраняет дерево в компактном бинарном формате. Узлы записываются
+// в порядке обхода в глубину: флаги узла, значение, количество дочерних узлов
+// и их байты (или битовая маска для узлов с большим количеством дочерних узлов).
+// Данные содержат заголовок с версией формата и контрольную сумму.
+func (array *Array[V]) MarshalBinary() ([]byte, error) {
+	e := binfmt.NewEncoder(binfmt.KindByteTrie)
+	e.Uvarint(uint64(array.count))
+	if err := array.root.encode(e, array.valueCodec()); err != nil {
+		return nil, err
+	}
+
+	return e.Finish(), nil
+}
+
+// UnmarshalBinary восстанавливает дерево из данных, сохраненных методом MarshalBinary,
+// заменяя текущее содержимое. При ошибке дерево не изменяется. Режим подсчета
+// и кодек значений сохраняются.
+func (array *Array[V]) UnmarshalBinary(data []byte) error {
+	d, err := binfmt.NewDecoder(data, binfmt.KindByteTrie)
+	if err != nil {
+		return err
+	}
+
+	count := d.Uvarint()
+	var root arrayNode[V]
+	if root.decode(d, array.valueCodec()) != count {
+		d.Fail("values count mismatch")
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+
+	if array.counted {
+		root.resetSizes()
+	}
+	array.root = root
+	array.count = int(count)
+
+	return nil
+}
+
+func (array *Array[V]) valueCodec() prefix_trees.ValueCodec[V] {
+	if array.codec == nil {
+		return prefix_trees.JSONCodec[V]{}
+	}
+
+	return array.codec
+}
+
+func (node *arrayNode[V]) encode(e *binfmt.Encoder, codec prefix_trees.ValueCodec[V]) error {
+	if node.value == nil {
+		e.Byte(0)
+	} else {
+		e.Byte(flagValue)
+		if err := binfmt.WriteValue(e, codec, *node.value); err != nil {
+			return err
+		}
+	}
+
+	e.Uvarint(uint64(len(node.children)))
+	if len(node.children) >= maskThreshold {
+		for _, word := range node.bits {
+			e.Uint64(word)
+		}
+	} else {
+		for i := range node.children {
+			e.Byte(node.children[i].k)
+		}
+	}
+
+	for i := range node.children {
+		if err := node.children[i].encode(e, codec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decode читает узел и его поддерево. Возвращает количество прочитанных значений.
+func (node *arrayNode[V]) decode(d *binfmt.Decoder, codec prefix_trees.ValueCodec[V]) uint64 {
+	count := uint64(0)
+
+	flags := d.Byte()
+	if flags&^flagValue != 0 {
+		d.Fail("invalid node flags")
+	}
+	if flags&flagValue != 0 {
+		value := binfmt.ReadValue(d, codec)
+		node.value = &value
+		count++
+	}
+
+	n := d.Uvarint()
+	if n > 256 {
+		d.Fail("invalid children count")
+	}
+	if d.Err() != nil {
+		return 0
+	}
+	if n == 0 {
+		return count
+	}
+
+	node.children = make([]arrayNode[V], n)
+	if n >= maskThreshold {
+		for i := range node.bits {
+			node.bits[i] = d.Uint64()
+		}
+		if node.bits.count() != int(n) {
+			d.Fail("invalid children mask")
+			return 0
+		}
+		// байты дочерних узлов восстанавливаются по маске в порядке возрастания
+		i := 0
+		for k := 0; k < 256; k++ {
+			if node.bits.isSet(byte(k)) {
+				node.children[i].k = byte(k)
+				i++
+			}
+		}
+	} else {
+		keys := d.Raw(int(n))
+		for i, k := range keys {
+			if i > 0 && k <= keys[i-1] {
+				d.Fail("unordered children")
+				return 0
+			}
+			node.bits.set(k)
+			node.children[i].k = k
+		}
+	}
+
+	for i := range node.children {
+		if d.Err() != nil {
+			return 0
+		}
+		count += node.children[i].decode(d, codec)
+		// Delete удаляет пустые листья, поэтому их нет в корректных данных
+		if child := &node.children[i]; child.value == nil && len(child.children) == 0 {
+			d.Fail("empty leaf node")
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,75 @@
+package byte_trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/binfmt"
+)
+
+func TestArray_UnmarshalBinary_EmptyLeaf(t *testing.T) {
+	tests := []struct {
+		name   string
+		encode func(e *binfmt.Encoder)
+	}{
+		{
+			name: "leaf under root",
+			encode: func(e *binfmt.Encoder) {
+				e.Uvarint(0)
+				e.Byte(0)
+				e.Uvarint(1)
+				e.Byte('a')
+				e.Byte(0)
+				e.Uvarint(0)
+			},
+		},
+		{
+			name: "leaf under value",
+			encode: func(e *binfmt.Encoder) {
+				e.Uvarint(2)
+				e.Byte(0)
+				e.Uvarint(1)
+				e.Byte('a')
+				e.Byte(flagValue)
+				e.Uvarint(1)
+				e.Raw([]byte("1"))
+				e.Uvarint(2)
+				e.Raw([]byte("bc"))
+				e.Byte(flagValue)
+				e.Uvarint(1)
+				e.Raw([]byte("2"))
+				e.Uvarint(0)
+				e.Byte(0)
+				e.Uvarint(0)
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := binfmt.NewEncoder(binfmt.KindByteTrie)
+			test.encode(e)
+			items := &Array[int]{}
+			items.Put([]byte("alpha"), 5)
+
+			err := items.UnmarshalBinary(e.Finish())
+
+			assert.ErrorIs(t, err, prefix_trees.ErrCorruptedData)
+			assert.Equal(t, 5, items.Get([]byte("alpha")))
+		})
+	}
+
+	t.Run("empty root", func(t *testing.T) {
+		e := binfmt.NewEncoder(binfmt.KindByteTrie)
+		e.Uvarint(0)
+		e.Byte(0)
+		e.Uvarint(0)
+		items := &Array[int]{}
+		items.Put([]byte("alpha"), 5)
+
+		err := items.UnmarshalBinary(e.Finish())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, items.Count())
+	})
+}
@@ -0,0 +1,116 @@
+package byte_trie_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/byte_shard_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestArray_MarshalBinary_BinaryKeys(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	// ключи со всеми значениями байт дают узлы с битовой маской вместо списка байт
+	for i := 0; i < 256; i++ {
+		items.Put([]byte{byte(i)}, i)
+		items.Put([]byte{0xFF, byte(i), 0x00}, -i)
+	}
+	for i := 0; i < 1000; i++ {
+		key := make([]byte, rand.Intn(8))
+		rand.Read(key)
+		items.Put(key, i)
+	}
+
+	data, err := items.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &byte_trie.Array[int]{}
+	err = restored.UnmarshalBinary(data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, items.Count(), restored.Count())
+	_ = items.Walk(func(key []byte, value int) error {
+		v, found := restored.Find(key)
+		assert.True(t, found, "at key: %v", key)
+		assert.Equal(t, value, v, "at key: %v", key)
+		return nil
+	})
+}
+
+func TestArray_MarshalBinary_ValueCodec(t *testing.T) {
+	items := &byte_trie.Array[uint64]{}
+	items.SetValueCodec(prefix_trees.VarintCodec[uint64]{})
+	items.Put([]byte("alpha"), 1)
+	items.Put([]byte("beta"), 1<<63)
+
+	data, err := items.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &byte_trie.Array[uint64]{}
+	restored.SetValueCodec(prefix_trees.VarintCodec[uint64]{})
+	err = restored.UnmarshalBinary(data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), restored.Get([]byte("alpha")))
+	assert.Equal(t, uint64(1<<63), restored.Get([]byte("beta")))
+}
+
+func TestArray_UnmarshalBinary_CountedMode(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	for i, country := range fixtures.Countries {
+		items.Put([]byte(country), i)
+	}
+	data, err := items.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := byte_trie.NewCountedArray[int]()
+	err = restored.UnmarshalBinary(data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, items.CountPrefix([]byte("C")), restored.CountPrefix([]byte("C")))
+	assert.Equal(t, items.Rank([]byte("M")), restored.Rank([]byte("M")))
+}
+
+func TestArray_UnmarshalBinary_OtherTrieKind(t *testing.T) {
+	items := &byte_shard_trie.Array[int]{}
+	items.Put([]byte("alpha"), 1)
+	data, err := items.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = (&byte_trie.Array[int]{}).UnmarshalBinary(data)
+
+	assert.ErrorIs(t, err, prefix_trees.ErrUnsupportedFormat)
+}
+
+func BenchmarkArray_UnmarshalBinary(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	t := byte_trie.Array[int]{}
+	t.SetValueCodec(prefix_trees.VarintCodec[int]{})
+	for n, city := range cities {
+		t.Put([]byte(city), n+1)
+	}
+	data, err := t.MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.ReportMetric(float64(len(data)), "bytes")
+
+	for i := 0; i < b.N; i++ {
+		restored := byte_trie.Array[int]{}
+		restored.SetValueCodec(prefix_trees.VarintCodec[int]{})
+		if err := restored.UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
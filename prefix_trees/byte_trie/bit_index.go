@@ -49,3 +49,20 @@ func (b *bitIndex) getOneNumber(n byte) int {
 func (b *bitIndex) splitN(n byte) (byte, byte) {
 	return n >> 6, n & 0x3F
 }
+
+// intersect оставляет в маске только биты, установленные в маске other.
+func (b *bitIndex) intersect(other *bitIndex) {
+	for i := range b {
+		b[i] &= other[i]
+	}
+}
+
+// count возвращает количество установленных битов.
+func (b *bitIndex) count() int {
+	n := 0
+	for _, word := range b {
+		n += bits.OnesCount64(word)
+	}
+
+	return n
+}
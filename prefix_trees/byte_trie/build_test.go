@@ -0,0 +1,79 @@
+package byte_trie_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestBuildFromSorted_Conformance(t *testing.T) {
+	trietest.RunBuild(t, func() prefix_trees.Trie[[]byte, int] {
+		return &byte_trie.Array[int]{}
+	}, func(seq prefix_trees.Seq[[]byte, int]) (prefix_trees.Trie[[]byte, int], error) {
+		return byte_trie.BuildFromSorted(seq)
+	}, byte_trie.ErrUnsortedKeys)
+}
+
+func TestBuildCountedFromSorted_Conformance(t *testing.T) {
+	trietest.RunBuild(t, func() prefix_trees.Trie[[]byte, int] {
+		return byte_trie.NewCountedArray[int]()
+	}, func(seq prefix_trees.Seq[[]byte, int]) (prefix_trees.Trie[[]byte, int], error) {
+		return byte_trie.BuildCountedFromSorted(seq)
+	}, byte_trie.ErrUnsortedKeys)
+}
+
+func TestBuildCountedFromSorted(t *testing.T) {
+	items := byte_trie.NewCountedArray[int]()
+	for i := 0; i < 2000; i++ {
+		key := randomKey()
+		items.Put([]byte(key), len(key))
+	}
+
+	built, err := byte_trie.BuildCountedFromSorted(items.Walk)
+
+	assert.NoError(t, err)
+	for _, prefix := range []string{"", "A", "Ba", "e", "zz"} {
+		assert.Equal(t, items.CountPrefix([]byte(prefix)), built.CountPrefix([]byte(prefix)), "at prefix: %s", prefix)
+		assert.Equal(t, items.Rank([]byte(prefix)), built.Rank([]byte(prefix)), "at key: %s", prefix)
+	}
+	for i := 0; i < items.Count(); i += 97 {
+		wantKey, wantValue, _ := items.Select(i)
+		key, value, ok := built.Select(i)
+		assert.True(t, ok)
+		assert.Equal(t, string(wantKey), string(key))
+		assert.Equal(t, wantValue, value)
+	}
+
+	// счетчики поддерживаются при изменении построенного дерева
+	built.Put([]byte("zzz"), 1)
+	assert.Equal(t, items.Count()+1, built.CountPrefix(nil))
+}
+
+func BenchmarkArray64_BuildFromSorted(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	// ключи упорядочиваются перебором заполненного дерева
+	t := byte_trie.Array[int]{}
+	for n, city := range cities {
+		t.Put([]byte(city), n+1)
+	}
+	keys := make([][]byte, 0, t.Count())
+	values := make([]int, 0, t.Count())
+	_ = t.Walk(func(key []byte, value int) error {
+		keys = append(keys, append([]byte(nil), key...))
+		values = append(values, value)
+		return nil
+	})
+	seq := prefix_trees.SliceSeq(keys, values)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := byte_trie.BuildFromSorted(seq); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
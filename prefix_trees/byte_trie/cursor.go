@@ -0,0 +1,102 @@
+package byte_trie
+
+import (
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/cursor"
+)
+
+// Cursor - курсор для перебора значений дерева в порядке возрастания ключей
+// с произвольной начальной позиции.
+//
+// Курсор хранит цепочку узлов от корня до текущего узла. После изменения дерева
+// (Put, Delete) курсор становится недействительным и его необходимо заново
+// позиционировать с помощью методов First, Last или Seek.
+type Cursor[V any] struct {
+	c *cursor.Cursor[*arrayNode[V], V]
+}
+
+// Cursor создает курсор для перебора значений дерева. Перед использованием
+// курсор необходимо позиционировать.
+func (array *Array[V]) Cursor() *Cursor[V] {
+	return &Cursor[V]{c: array.cursor()}
+}
+
+// Range перебирает в порядке возрастания значения с ключами в диапазоне от from
+// до to и для каждого из них вызывает функцию f.
+func (array *Array[V]) Range(from, to prefix_trees.Bound, f func(key []byte, value V) error) error {
+	return prefix_trees.Range[V](array.Cursor(), from, to, f)
+}
+
+// Valid возвращает true, если курсор указывает на существующее значение.
+func (c *Cursor[V]) Valid() bool {
+	return c.c.Valid()
+}
+
+// Key возвращает ключ текущего значения. Слайс действителен до следующего
+// перемещения курсора.
+func (c *Cursor[V]) Key() []byte {
+	return c.c.Key()
+}
+
+// Value возвращает текущее значение.
+func (c *Cursor[V]) Value() V {
+	return c.c.Value()
+}
+
+// First перемещает курсор на наименьший ключ.
+func (c *Cursor[V]) First() bool {
+	return c.c.First()
+}
+
+// Last перемещает курсор на наибольший ключ.
+func (c *Cursor[V]) Last() bool {
+	return c.c.Last()
+}
+
+// Seek перемещает курсор на наименьший ключ, больший или равный key.
+func (c *Cursor[V]) Seek(key []byte) bool {
+	return c.c.Seek(key)
+}
+
+// Next перемещает курсор на следующий ключ в порядке возрастания.
+func (c *Cursor[V]) Next() bool {
+	return c.c.Next()
+}
+
+// Prev перемещает курсор на предыдущий ключ в порядке возрастания.
+func (c *Cursor[V]) Prev() bool {
+	return c.c.Prev()
+}
+
+func (array *Array[V]) cursor() *cursor.Cursor[*arrayNode[V], V] {
+	return cursor.New[*arrayNode[V], V](&array.root)
+}
+
+// Методы перехода между узлами для курсора (интерфейс cursor.Node).
+
+func (node *arrayNode[V]) HasValue() bool {
+	return node.value != nil
+}
+
+func (node *arrayNode[V]) Value() V {
+	return *node.value
+}
+
+func (node *arrayNode[V]) ChildCount() int {
+	return len(node.children)
+}
+
+func (node *arrayNode[V]) Child(i int) *arrayNode[V] {
+	return &node.children[i]
+}
+
+func (node *arrayNode[V]) AppendLabel(key []byte) []byte {
+	return append(key, node.k)
+}
+
+// SeekChild находит дочерний узел по первому байту ключа. Количество дочерних
+// узлов с меньшими байтами является индексом первого дочернего узла с большим
+// или равным байтом.
+func (node *arrayNode[V]) SeekChild(key []byte) (int, int, bool) {
+	return node.bits.getOneNumber(key[0]), 1, node.bits.isSet(key[0])
+}
@@ -0,0 +1,93 @@
+package byte_trie
+
+// FuzzySearch перебирает все значения, ключи которых отличаются от key не более чем
+// на maxDistance операций вставки, удаления или замены байта (расстояние Левенштейна),
+// и для каждого из них вызывает функцию f с найденным ключом и расстоянием до него.
+//
+// При обходе дерева для каждой глубины вычисляется строка матрицы расстояний
+// между префиксом узла и ключом key. Если минимальное значение в строке превышает
+// maxDistance, то поддерево узла пропускается.
+func (array *Array[V]) FuzzySearch(key []byte, maxDistance int, f func(key []byte, value V, distance int) error) error {
+	s := fuzzySearch[V]{key: key, maxDistance: maxDistance, f: f}
+
+	// первая строка матрицы - расстояния от пустого префикса до префиксов ключа
+	row := s.row(0)
+	for i := range row {
+		row[i] = i
+	}
+	if array.root.value != nil && row[len(key)] <= maxDistance {
+		if err := f(nil, *array.root.value, row[len(key)]); err != nil {
+			return err
+		}
+	}
+
+	return s.search(&array.root, 0)
+}
+
+type fuzzySearch[V any] struct {
+	key         []byte
+	maxDistance int
+	f           func(key []byte, value V, distance int) error
+	// префикс текущего узла
+	prefix []byte
+	// строки матрицы расстояний для каждой глубины обхода
+	rows [][]int
+}
+
+func (s *fuzzySearch[V]) search(node *arrayNode[V], depth int) error {
+	prev := s.row(depth)
+	row := s.row(depth + 1)
+	n := len(s.key)
+
+	for i := range node.children {
+		child := &node.children[i]
+
+		row[0] = prev[0] + 1
+		best := row[0]
+		for j := 1; j <= n; j++ {
+			// замена (или совпадение) байта
+			d := prev[j-1]
+			if s.key[j-1] != child.k {
+				d++
+			}
+			// удаление байта из ключа
+			if prev[j]+1 < d {
+				d = prev[j] + 1
+			}
+			// вставка байта в ключ
+			if row[j-1]+1 < d {
+				d = row[j-1] + 1
+			}
+			row[j] = d
+			if d < best {
+				best = d
+			}
+		}
+
+		// все ключи поддерева находятся дальше допустимого расстояния
+		if best > s.maxDistance {
+			continue
+		}
+
+		s.prefix = append(s.prefix[:depth], child.k)
+		if child.value != nil && row[n] <= s.maxDistance {
+			if err := s.f(s.prefix, *child.value, row[n]); err != nil {
+				return err
+			}
+		}
+		if err := s.search(child, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// row возвращает строку матрицы расстояний для глубины depth.
+func (s *fuzzySearch[V]) row(depth int) []int {
+	for len(s.rows) <= depth {
+		s.rows = append(s.rows, make([]int, len(s.key)+1))
+	}
+
+	return s.rows[depth]
+}
@@ -0,0 +1,114 @@
+package byte_trie_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestArray_EncodeJSON_BinaryKeys(t *testing.T) {
+	keys := [][]byte{
+		{0xFF, 0xFE},
+		[]byte("back\\slash"),
+		[]byte(`\xff`),
+		[]byte("Привет"),
+		{'a', 0x80, 'b'},
+	}
+	tests := []struct {
+		name     string
+		encoding prefix_trees.KeyEncoding
+		want     string
+	}{
+		{
+			name:     "escaped",
+			encoding: prefix_trees.KeyEscaped,
+			want:     `{"Привет":4,"\\\\xff":3,"a\\x80b":5,"back\\\\slash":2,"\\xff\\xfe":1}`,
+		},
+		{
+			name:     "base64",
+			encoding: prefix_trees.KeyBase64,
+			want:     `{"0J/RgNC40LLQtdGC":4,"XHhmZg==":3,"YYBi":5,"YmFja1xzbGFzaA==":2,"//4=":1}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := &byte_trie.Array[int]{}
+			for i, key := range keys {
+				items.Put(key, i+1)
+			}
+			option := prefix_trees.WithKeyEncoding(test.encoding)
+
+			var data bytes.Buffer
+			err := items.EncodeJSON(&data, option)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.JSONEq(t, test.want, data.String())
+
+			restored := &byte_trie.Array[int]{}
+			err = restored.DecodeJSON(&data, option)
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(keys), restored.Count())
+			for i, key := range keys {
+				assert.Equal(t, i+1, restored.Get(key), "at key: %q", key)
+			}
+		})
+	}
+}
+
+func TestArray_DecodeJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		encoding prefix_trees.KeyEncoding
+	}{
+		{name: "not an object", data: `[1, 2]`},
+		{name: "invalid value", data: `{"alpha":"one"}`},
+		{name: "unclosed object", data: `{"alpha":1`},
+		{name: "invalid escape", data: `{"\\q":1}`, encoding: prefix_trees.KeyEscaped},
+		{name: "short escape", data: `{"\\x4":1}`, encoding: prefix_trees.KeyEscaped},
+		{name: "invalid base64", data: `{"!!":1}`, encoding: prefix_trees.KeyBase64},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := &byte_trie.Array[int]{}
+
+			err := items.DecodeJSON(strings.NewReader(test.data), prefix_trees.WithKeyEncoding(test.encoding))
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestArray_DecodeJSON_Null(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	items.Put([]byte("alpha"), 1)
+
+	err := items.DecodeJSON(strings.NewReader(`null`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, items.Count())
+}
+
+func BenchmarkArray_EncodeJSON(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	t := byte_trie.Array[int]{}
+	for n, city := range cities {
+		t.Put([]byte(city), n+1)
+	}
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := t.EncodeJSON(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package byte_trie
+
+import (
+	"math/bits"
+
+	"github.com/strider2038/algos/prefix_trees/internal/glob"
+)
+
+// ErrInvalidPattern - ошибка разбора шаблона поиска.
+var ErrInvalidPattern = glob.ErrInvalidPattern
+
+// Match перебирает в порядке возрастания все значения, ключи которых соответствуют
+// шаблону pattern, и для каждого из них вызывает функцию f.
+//
+// Поддерживаемые элементы шаблона:
+//
+//	?       любой байт
+//	*       любая последовательность байт (в том числе пустая)
+//	[abc]   любой байт из перечисленных
+//	[a-z]   любой байт из диапазона
+//	[!abc]  любой байт, кроме перечисленных (также [^abc])
+//	\c      байт c без специального значения
+//
+// Шаблон преобразуется в недетерминированный автомат, состояния которого
+// отслеживаются одновременно при обходе дерева. Допустимые байты всех состояний
+// объединяются в битовую маску, которая пересекается с маской дочерних узлов,
+// поэтому ветви без совпадений не посещаются.
+func (array *Array[V]) Match(pattern []byte, f func(key []byte, value V) error) error {
+	automaton, err := glob.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	m := matcher[V]{automaton: automaton, f: f}
+
+	return m.match(&array.root, 0)
+}
+
+type matcher[V any] struct {
+	automaton *glob.Automaton
+	f         func(key []byte, value V) error
+	// ключ текущего узла
+	key []byte
+}
+
+func (m *matcher[V]) match(node *arrayNode[V], depth int) error {
+	if node.value != nil && m.automaton.Accepts(depth) {
+		if err := m.f(m.key, *node.value); err != nil {
+			return err
+		}
+	}
+
+	// маска допустимых байт всех активных состояний
+	allowed := bitIndex(m.automaton.Allowed(depth))
+	allowed.intersect(&node.bits)
+
+	// перебираем только дочерние узлы, байты которых допустимы шаблоном
+	for hi, word := range allowed {
+		for word != 0 {
+			k := byte(hi<<6 | bits.TrailingZeros64(word))
+			word &= word - 1
+
+			if !m.automaton.Step(depth, k) {
+				continue
+			}
+
+			m.key = append(m.key[:depth], k)
+			child := &node.children[node.bits.getOneNumber(k)]
+			if err := m.match(child, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
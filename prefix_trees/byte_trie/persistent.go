@@ -0,0 +1,171 @@
+package byte_trie
+
+// Persistent неизменяемый (персистентный) вариант префиксного дерева Array.
+//
+// Методы Put и Delete не изменяют дерево, а возвращают его новую версию. В новой
+// версии копируются только узлы на пути от корня к изменяемому ключу (копирование
+// пути), остальные узлы используются совместно со старой версией. Поэтому любая
+// версия может безопасно читаться из нескольких горутин, пока другие горутины
+// создают новые версии.
+//
+// Нулевое значение является пустым деревом.
+type Persistent[V any] struct {
+	array Array[V]
+}
+
+// Snapshot возвращает снимок текущей версии дерева за O(1). Так как версия
+// неизменяема, то снимком является сама версия.
+func (p *Persistent[V]) Snapshot() *Persistent[V] {
+	return p
+}
+
+func (p *Persistent[V]) Count() int {
+	return p.array.Count()
+}
+
+func (p *Persistent[V]) Get(key []byte) V {
+	return p.array.Get(key)
+}
+
+func (p *Persistent[V]) Find(key []byte) (V, bool) {
+	return p.array.Find(key)
+}
+
+// Put возвращает новую версию дерева со значением value по ключу key.
+//
+// Каждый вызов копирует массивы дочерних узлов всех узлов на пути к ключу, поэтому
+// стоимость записи пропорциональна сумме количества дочерних узлов на пути, а не
+// длине ключа. Узел занимает 72 байта, и для корня с 256 дочерними узлами (двоичные
+// ключи) одна запись копирует около 18 КБ только на первом уровне. Для частых
+// изменений следует использовать Array, а Persistent - когда нужны снимки.
+func (p *Persistent[V]) Put(key []byte, value V) *Persistent[V] {
+	root, added := p.array.root.putCopy(key, &value)
+
+	next := &Persistent[V]{array: Array[V]{root: root, count: p.array.count}}
+	if added {
+		next.array.count++
+	}
+
+	return next
+}
+
+// Delete возвращает новую версию дерева без значения по ключу key. Опустевшие узлы
+// в новую версию не попадают. Если ключ не найден, то возвращается текущая версия.
+func (p *Persistent[V]) Delete(key []byte) *Persistent[V] {
+	root, deleted := p.array.root.deleteCopy(key)
+	if !deleted {
+		return p
+	}
+
+	return &Persistent[V]{array: Array[V]{root: root, count: p.array.count - 1}}
+}
+
+// Walk перебирает дерево и для каждого существующего узла вызывает функцию f.
+func (p *Persistent[V]) Walk(f func(key []byte, value V) error) error {
+	return p.array.Walk(f)
+}
+
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (p *Persistent[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	return p.array.WalkPrefix(prefix, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (p *Persistent[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return p.array.KeysWithPrefix(prefix, limit)
+}
+
+// LongestPrefix находит самый длинный из хранящихся в дереве ключей, который является
+// префиксом ключа key. Возвращает длину найденного ключа, его значение и флаг наличия.
+func (p *Persistent[V]) LongestPrefix(key []byte) (int, V, bool) {
+	return p.array.LongestPrefix(key)
+}
+
+func (p *Persistent[V]) MarshalJSON() ([]byte, error) {
+	return p.array.MarshalJSON()
+}
+
+// putCopy возвращает копию узла, в поддереве которой по ключу key установлено
+// значение value. Флаг added равен true, если ключ не существовал. Массив дочерних
+// узлов копируется целиком на каждом уровне.
+func (node *arrayNode[V]) putCopy(key []byte, value *V) (arrayNode[V], bool) {
+	n := *node
+
+	if len(key) == 0 {
+		added := n.value == nil
+		n.value = value
+
+		return n, added
+	}
+
+	k := key[0]
+	i := node.bits.getOneNumber(k)
+
+	if node.bits.isSet(k) {
+		// копируем массив дочерних узлов с замененным узлом
+		child, added := node.children[i].putCopy(key[1:], value)
+		n.children = make([]arrayNode[V], len(node.children))
+		copy(n.children, node.children)
+		n.children[i] = child
+
+		return n, added
+	}
+
+	// копируем массив дочерних узлов со вставкой новой цепочки по индексу i
+	child, _ := (&arrayNode[V]{k: k}).putCopy(key[1:], value)
+	n.bits.set(k)
+	n.children = make([]arrayNode[V], len(node.children)+1)
+	copy(n.children, node.children[:i])
+	n.children[i] = child
+	copy(n.children[i+1:], node.children[i:])
+
+	return n, true
+}
+
+// deleteCopy возвращает копию узла, в поддереве которой удалено значение по ключу key.
+// Флаг deleted равен false, если ключ не найден (в этом случае копия не создается).
+func (node *arrayNode[V]) deleteCopy(key []byte) (arrayNode[V], bool) {
+	if len(key) == 0 {
+		if node.value == nil {
+			return *node, false
+		}
+		n := *node
+		n.value = nil
+
+		return n, true
+	}
+
+	k := key[0]
+	// если индекс отсутствует в маске, то такого элемента нет в дереве
+	if !node.bits.isSet(k) {
+		return *node, false
+	}
+
+	i := node.bits.getOneNumber(k)
+	child, deleted := node.children[i].deleteCopy(key[1:])
+	if !deleted {
+		return *node, false
+	}
+
+	n := *node
+	if child.value == nil && len(child.children) == 0 {
+		// опустевший лист не копируется в новую версию
+		n.bits.unset(k)
+		n.children = nil
+		if len(node.children) > 1 {
+			n.children = make([]arrayNode[V], 0, len(node.children)-1)
+			n.children = append(n.children, node.children[:i]...)
+			n.children = append(n.children, node.children[i+1:]...)
+		}
+
+		return n, true
+	}
+
+	n.children = make([]arrayNode[V], len(node.children))
+	copy(n.children, node.children)
+	n.children[i] = child
+
+	return n, true
+}
@@ -0,0 +1,131 @@
+package byte_trie_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+// persistentTrie - изменяемая обертка над персистентным деревом для общего набора проверок.
+type persistentTrie struct {
+	*byte_trie.Persistent[int]
+}
+
+func (t *persistentTrie) Put(key []byte, value int) {
+	t.Persistent = t.Persistent.Put(key, value)
+}
+
+func (t *persistentTrie) Delete(key []byte) {
+	t.Persistent = t.Persistent.Delete(key)
+}
+
+func TestPersistent_Conformance(t *testing.T) {
+	trietest.Run(t, func() prefix_trees.Trie[[]byte, int] {
+		return &persistentTrie{Persistent: &byte_trie.Persistent[int]{}}
+	})
+}
+
+func TestPersistent_Versions(t *testing.T) {
+	type version struct {
+		trie *byte_trie.Persistent[int]
+		m    map[string]int
+	}
+
+	current := version{trie: &byte_trie.Persistent[int]{}, m: map[string]int{}}
+	versions := []version{current}
+
+	for i := 0; i < 3000; i++ {
+		key := randomKey()
+		m := make(map[string]int, len(current.m)+1)
+		for k, v := range current.m {
+			m[k] = v
+		}
+
+		var trie *byte_trie.Persistent[int]
+		if rand.Intn(3) == 0 {
+			trie = current.trie.Delete([]byte(key))
+			delete(m, key)
+		} else {
+			trie = current.trie.Put([]byte(key), i)
+			m[key] = i
+		}
+
+		current = version{trie: trie.Snapshot(), m: m}
+		if i%100 == 0 {
+			versions = append(versions, current)
+		}
+	}
+	versions = append(versions, current)
+
+	// каждая из версий должна сохранить свое содержимое
+	for _, v := range versions {
+		assert.Equal(t, len(v.m), v.trie.Count())
+
+		got := map[string]int{}
+		err := v.trie.Walk(func(key []byte, value int) error {
+			got[string(key)] = value
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, v.m, got)
+	}
+}
+
+func TestPersistent_Delete_MissingKey(t *testing.T) {
+	v1 := (&byte_trie.Persistent[int]{}).Put([]byte("alpha"), 1)
+	v2 := v1.Delete([]byte("beta"))
+
+	assert.Same(t, v1, v2)
+}
+
+// BenchmarkPersistent_Put сравнивает перезапись значений в изменяемом и персистентном
+// дереве. Персистентное дерево копирует массивы дочерних узлов на всем пути к ключу,
+// поэтому стоимость записи растет с количеством дочерних узлов у узлов пути.
+func BenchmarkPersistent_Put(b *testing.B) {
+	random := rand.New(rand.NewSource(1))
+	binaryKeys := make([][]byte, 100_000)
+	for i := range binaryKeys {
+		binaryKeys[i] = make([]byte, 8)
+		random.Read(binaryKeys[i])
+	}
+	countries := make([][]byte, len(fixtures.Countries))
+	for i, country := range fixtures.Countries {
+		countries[i] = []byte(country)
+	}
+
+	benchmarks := []struct {
+		name string
+		keys [][]byte
+	}{
+		{name: "countries", keys: countries},
+		{name: "binary keys", keys: binaryKeys},
+	}
+	for _, bm := range benchmarks {
+		array := &byte_trie.Array[int]{}
+		persistent := &byte_trie.Persistent[int]{}
+		for i, key := range bm.keys {
+			array.Put(key, i)
+			persistent = persistent.Put(key, i)
+		}
+
+		b.Run(bm.name+"/array", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				array.Put(bm.keys[i%len(bm.keys)], i)
+			}
+		})
+		b.Run(bm.name+"/persistent", func(b *testing.B) {
+			b.ReportAllocs()
+			version := persistent
+			for i := 0; i < b.N; i++ {
+				version = version.Put(bm.keys[i%len(bm.keys)], i)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package byte_trie
+
+import "github.com/strider2038/algos/prefix_trees"
+
+// NewCountedArray создает дерево в режиме подсчета, в котором каждый узел хранит
+// количество значений в своем поддереве. Режим ускоряет методы CountPrefix,
+// Rank и Select до O(len(key)) ценой дополнительного прохода по ключу
+// при добавлении и удалении значений.
+//
+// Без режима подсчета эти методы также работают, но перебирают поддеревья.
+func NewCountedArray[V any]() *Array[V] {
+	return &Array[V]{counted: true}
+}
+
+// BuildCountedFromSorted строит дерево в режиме подсчета из последовательности
+// упорядоченных ключей (см. BuildFromSorted). Количество значений в поддеревьях
+// вычисляется одним проходом после построения.
+func BuildCountedFromSorted[V any](seq prefix_trees.Seq[[]byte, V]) (*Array[V], error) {
+	array := NewCountedArray[V]()
+	if err := array.build(seq); err != nil {
+		return nil, err
+	}
+	array.root.resetSizes()
+
+	return array, nil
+}
+
+// CountPrefix возвращает количество значений, ключи которых начинаются
+// с префикса prefix (включая сам префикс).
+func (array *Array[V]) CountPrefix(prefix []byte) int {
+	node := &array.root
+
+	for _, k := range prefix {
+		// если индекс отсутствует в маске, то таких элементов нет в дереве
+		if !node.bits.isSet(k) {
+			return 0
+		}
+		// по номеру символа находим индекс следующего подузла дерева
+		node = &node.children[node.bits.getOneNumber(k)]
+	}
+
+	return array.sizeOf(node)
+}
+
+// Rank возвращает количество ключей, строго меньших key.
+func (array *Array[V]) Rank(key []byte) int {
+	node := &array.root
+	rank := 0
+
+	for _, k := range key {
+		// ключ узла является префиксом key и потому меньше него
+		if node.value != nil {
+			rank++
+		}
+		// добавляем все поддеревья с меньшими байтами
+		i := node.bits.getOneNumber(k)
+		for j := 0; j < i; j++ {
+			rank += array.sizeOf(&node.children[j])
+		}
+		// если индекс отсутствует в маске, то больше меньших ключей нет
+		if !node.bits.isSet(k) {
+			return rank
+		}
+		node = &node.children[i]
+	}
+
+	return rank
+}
+
+// Select возвращает ключ с порядковым номером i (начиная с нуля) в порядке
+// возрастания ключей и его значение.
+func (array *Array[V]) Select(i int) ([]byte, V, bool) {
+	var zero V
+	if i < 0 || i >= array.count {
+		return nil, zero, false
+	}
+
+	node := &array.root
+	var key []byte
+
+	for {
+		if node.value != nil {
+			if i == 0 {
+				return key, *node.value, true
+			}
+			i--
+		}
+
+		// находим поддерево, содержащее i-й ключ
+		next := -1
+		for j := range node.children {
+			size := array.sizeOf(&node.children[j])
+			if i < size {
+				next = j
+				break
+			}
+			i -= size
+		}
+		if next < 0 {
+			return nil, zero, false
+		}
+
+		node = &node.children[next]
+		key = append(key, node.k)
+	}
+}
+
+// sizeOf возвращает количество значений в поддереве узла. В режиме подсчета
+// используется сохраненное значение, иначе поддерево перебирается.
+func (array *Array[V]) sizeOf(node *arrayNode[V]) int {
+	if array.counted {
+		return int(node.size)
+	}
+
+	size := 0
+	if node.value != nil {
+		size++
+	}
+	for i := range node.children {
+		size += array.sizeOf(&node.children[i])
+	}
+
+	return size
+}
+
+// addSize изменяет на delta количество значений во всех узлах на пути по ключу key.
+// Все узлы пути должны существовать.
+func (node *arrayNode[V]) addSize(key []byte, delta int) {
+	node.size = uint32(int(node.size) + delta)
+
+	for _, k := range key {
+		node = &node.children[node.bits.getOneNumber(k)]
+		node.size = uint32(int(node.size) + delta)
+	}
+}
+
+// resetSizes пересчитывает количество значений во всех узлах поддерева
+// и возвращает размер поддерева.
+func (node *arrayNode[V]) resetSizes() uint32 {
+	node.size = 0
+	if node.value != nil {
+		node.size++
+	}
+	for i := range node.children {
+		node.size += node.children[i].resetSizes()
+	}
+
+	return node.size
+}
@@ -0,0 +1,76 @@
+package byte_trie_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+)
+
+func TestCountedArray_Conformance(t *testing.T) {
+	trietest.Run(t, func() prefix_trees.Trie[[]byte, int] {
+		return byte_trie.NewCountedArray[int]()
+	})
+}
+
+func TestArray_RankSelect(t *testing.T) {
+	tests := []struct {
+		name  string
+		items *byte_trie.Array[int]
+	}{
+		{name: "counted", items: byte_trie.NewCountedArray[int]()},
+		{name: "not counted", items: &byte_trie.Array[int]{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items := test.items
+			keys := map[string]bool{}
+			for i := 0; i < 2000; i++ {
+				key := randomKey()
+				items.Put([]byte(key), len(key))
+				keys[key] = true
+			}
+			// удаляем часть ключей, чтобы проверить поддержку счетчиков при удалении
+			for key := range keys {
+				if len(key)%3 == 0 {
+					items.Delete([]byte(key))
+					delete(keys, key)
+				}
+			}
+			sorted := make([]string, 0, len(keys))
+			for key := range keys {
+				sorted = append(sorted, key)
+			}
+			sort.Strings(sorted)
+
+			for i, key := range sorted {
+				k, value, ok := items.Select(i)
+				if assert.True(t, ok) {
+					assert.Equal(t, key, string(k))
+					assert.Equal(t, len(key), value)
+				}
+			}
+			_, _, ok := items.Select(len(sorted))
+			assert.False(t, ok)
+			_, _, ok = items.Select(-1)
+			assert.False(t, ok)
+
+			for i := 0; i < 500; i++ {
+				probe := randomKey()
+				assert.Equal(t, sort.SearchStrings(sorted, probe), items.Rank([]byte(probe)), "at probe: %s", probe)
+
+				count := 0
+				for _, key := range sorted {
+					if strings.HasPrefix(key, probe) {
+						count++
+					}
+				}
+				assert.Equal(t, count, items.CountPrefix([]byte(probe)), "at probe: %s", probe)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package prefix_trees
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnsupportedFormat - данные не являются сериализованным деревом
+	// этого типа или записаны в неподдерживаемой версии формата.
+	ErrUnsupportedFormat = errors.New("unsupported trie data format")
+	// ErrCorruptedData - сериализованные данные повреждены: не совпадает
+	// контрольная сумма или нарушена структура дерева.
+	ErrCorruptedData = errors.New("corrupted trie data")
+)
+
+// ValueCodec кодирует значения дерева при бинарной сериализации.
+type ValueCodec[V any] interface {
+	// AppendValue добавляет закодированное значение к data и возвращает результат.
+	AppendValue(data []byte, value V) ([]byte, error)
+	// DecodeValue декодирует значение, записанное методом AppendValue.
+	DecodeValue(data []byte) (V, error)
+}
+
+// JSONCodec кодирует значения в формате JSON. Подходит для любых типов значений
+// и используется деревьями по умолчанию.
+type JSONCodec[V any] struct{}
+
+func (JSONCodec[V]) AppendValue(data []byte, value V) ([]byte, error) {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, v...), nil
+}
+
+func (JSONCodec[V]) DecodeValue(data []byte) (V, error) {
+	var value V
+	err := json.Unmarshal(data, &value)
+
+	return value, err
+}
+
+// Integer - целочисленные типы значений для VarintCodec.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// VarintCodec кодирует целочисленные значения в компактном формате
+// переменной длины (zigzag varint).
+type VarintCodec[V Integer] struct{}
+
+func (VarintCodec[V]) AppendValue(data []byte, value V) ([]byte, error) {
+	return binary.AppendVarint(data, int64(value)), nil
+}
+
+func (VarintCodec[V]) DecodeValue(data []byte) (V, error) {
+	v, n := binary.Varint(data)
+	if n != len(data) {
+		return 0, fmt.Errorf("%w: invalid varint value", ErrCorruptedData)
+	}
+
+	return V(v), nil
+}
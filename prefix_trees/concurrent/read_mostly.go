@@ -0,0 +1,189 @@
+package concurrent
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// ReadMostly контейнер для редко изменяемых словарей на основе любого префиксного дерева.
+//
+// Читатели обращаются к неизменяемой (замороженной) версии дерева через atomic.Pointer
+// без каких-либо блокировок. Писатели собирают изменения в новой версии дерева
+// и публикуют ее атомарной заменой указателя (по принципу RCU, read-copy-update).
+// Новая версия строится копированием текущей с помощью Walk и Put, поэтому контейнер
+// работает с любой реализацией prefix_trees.Trie. Стоимость копирования O(n),
+// поэтому изменения следует объединять в пакеты.
+//
+// Если задана функция onRelease, то она вызывается для каждой замененной версии,
+// как только эту версию перестают использовать все читатели. Функция вызывается
+// в горутине, освободившей последнюю ссылку. В этом режиме читатели учитывают
+// ссылки на версию атомарным счетчиком.
+type ReadMostly[K prefix_trees.Key, V any] struct {
+	current   atomic.Pointer[version[K, V]]
+	newTrie   func() prefix_trees.Trie[K, V]
+	onRelease func(trie prefix_trees.Trie[K, V])
+	// блокировка писателей, читатели ее не используют
+	mu sync.Mutex
+}
+
+// version - опубликованная версия дерева.
+type version[K prefix_trees.Key, V any] struct {
+	trie prefix_trees.Trie[K, V]
+	// количество ссылок на версию: одна ссылка принадлежит контейнеру, пока
+	// версия является текущей, остальные - читателям
+	refs atomic.Int64
+}
+
+// NewReadMostly создает контейнер с пустым деревом. Функция newTrie создает пустые
+// деревья для новых версий. Функция onRelease может быть nil.
+func NewReadMostly[K prefix_trees.Key, V any](
+	newTrie func() prefix_trees.Trie[K, V],
+	onRelease func(trie prefix_trees.Trie[K, V]),
+) *ReadMostly[K, V] {
+	rm := &ReadMostly[K, V]{newTrie: newTrie, onRelease: onRelease}
+	rm.publish(newTrie())
+
+	return rm
+}
+
+// View вызывает функцию f с текущей версией дерева. Версия остается неизменной
+// на все время вызова, даже если параллельно публикуются новые версии.
+func (rm *ReadMostly[K, V]) View(f func(trie prefix_trees.Reader[K, V]) error) error {
+	v := rm.acquire()
+	defer rm.release(v)
+
+	return f(v.trie)
+}
+
+// Update создает новую версию дерева: копирует в нее текущую версию, применяет
+// к ней изменения функцией f и атомарно публикует. Если функция f вернула ошибку,
+// то новая версия отбрасывается. Писатели выполняются последовательно.
+func (rm *ReadMostly[K, V]) Update(f func(trie prefix_trees.Trie[K, V]) error) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	trie := rm.newTrie()
+	err := rm.current.Load().trie.Walk(func(key K, value V) error {
+		// ключ копируется, так как слайс ключа используется деревом повторно
+		trie.Put(K(string(key)), value)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := f(trie); err != nil {
+		return err
+	}
+
+	rm.publish(trie)
+
+	return nil
+}
+
+// Store публикует заранее построенное дерево в качестве новой версии. После
+// вызова дерево не должно изменяться.
+func (rm *ReadMostly[K, V]) Store(trie prefix_trees.Trie[K, V]) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	rm.publish(trie)
+}
+
+func (rm *ReadMostly[K, V]) Count() int {
+	v := rm.acquire()
+	defer rm.release(v)
+
+	return v.trie.Count()
+}
+
+func (rm *ReadMostly[K, V]) Get(key K) V {
+	v := rm.acquire()
+	defer rm.release(v)
+
+	return v.trie.Get(key)
+}
+
+func (rm *ReadMostly[K, V]) Find(key K) (V, bool) {
+	v := rm.acquire()
+	defer rm.release(v)
+
+	return v.trie.Find(key)
+}
+
+// Walk перебирает текущую версию дерева и для каждого существующего значения
+// вызывает функцию f.
+func (rm *ReadMostly[K, V]) Walk(f func(key K, value V) error) error {
+	v := rm.acquire()
+	defer rm.release(v)
+
+	return v.trie.Walk(f)
+}
+
+// WalkPrefix перебирает в текущей версии дерева все значения, ключи которых
+// начинаются с префикса prefix, и для каждого из них вызывает функцию f.
+func (rm *ReadMostly[K, V]) WalkPrefix(prefix K, f func(key K, value V) error) error {
+	v := rm.acquire()
+	defer rm.release(v)
+
+	return v.trie.WalkPrefix(prefix, f)
+}
+
+// KeysWithPrefix возвращает ключи текущей версии дерева, начинающиеся с префикса
+// prefix. Если limit больше нуля, то возвращается не более limit ключей.
+func (rm *ReadMostly[K, V]) KeysWithPrefix(prefix K, limit int) []K {
+	v := rm.acquire()
+	defer rm.release(v)
+
+	return v.trie.KeysWithPrefix(prefix, limit)
+}
+
+func (rm *ReadMostly[K, V]) MarshalJSON() ([]byte, error) {
+	v := rm.acquire()
+	defer rm.release(v)
+
+	return v.trie.MarshalJSON()
+}
+
+// publish атомарно заменяет текущую версию и освобождает ссылку контейнера
+// на предыдущую версию.
+func (rm *ReadMostly[K, V]) publish(trie prefix_trees.Trie[K, V]) {
+	v := &version[K, V]{trie: trie}
+	v.refs.Store(1)
+
+	if old := rm.current.Swap(v); old != nil {
+		rm.release(old)
+	}
+}
+
+// acquire возвращает текущую версию. Если задана функция onRelease, то
+// увеличивает счетчик ссылок на версию.
+func (rm *ReadMostly[K, V]) acquire() *version[K, V] {
+	if rm.onRelease == nil {
+		return rm.current.Load()
+	}
+
+	for {
+		v := rm.current.Load()
+		refs := v.refs.Load()
+		// версия с нулевым счетчиком уже освобождена и заменена новой,
+		// поэтому повторяем чтение текущей версии
+		if refs > 0 && v.refs.CompareAndSwap(refs, refs+1) {
+			return v
+		}
+	}
+}
+
+// release освобождает ссылку на версию. Когда ссылок не остается, вызывается
+// функция onRelease.
+func (rm *ReadMostly[K, V]) release(v *version[K, V]) {
+	if rm.onRelease == nil {
+		return
+	}
+
+	if v.refs.Add(-1) == 0 {
+		rm.onRelease(v.trie)
+	}
+}
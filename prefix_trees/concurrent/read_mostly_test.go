@@ -0,0 +1,206 @@
+package concurrent_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/alphabet_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_shard_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_suffix_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/concurrent"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestReadMostly_Update(t *testing.T) {
+	tests := []struct {
+		name    string
+		newTrie func() prefix_trees.Trie[[]byte, int]
+	}{
+		{
+			name:    "byte trie",
+			newTrie: func() prefix_trees.Trie[[]byte, int] { return &byte_trie.Array[int]{} },
+		},
+		{
+			name:    "byte shard trie",
+			newTrie: func() prefix_trees.Trie[[]byte, int] { return &byte_shard_trie.Array[int]{} },
+		},
+		{
+			name:    "byte suffix trie",
+			newTrie: func() prefix_trees.Trie[[]byte, int] { return &byte_suffix_trie.Array[int]{} },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rm := concurrent.NewReadMostly(test.newTrie, nil)
+
+			err := rm.Update(func(trie prefix_trees.Trie[[]byte, int]) error {
+				for i, country := range fixtures.Countries {
+					trie.Put([]byte(country), i+1)
+				}
+				return nil
+			})
+			assert.NoError(t, err)
+			err = rm.Update(func(trie prefix_trees.Trie[[]byte, int]) error {
+				trie.Delete([]byte("Chad"))
+				trie.Put([]byte("Chadland"), 1000)
+				return nil
+			})
+			assert.NoError(t, err)
+
+			_, found := rm.Find([]byte("Chad"))
+			assert.False(t, found)
+			assert.Equal(t, 1000, rm.Get([]byte("Chadland")))
+			assert.Equal(t, 1, rm.Get([]byte("Afghanistan")))
+			assert.Equal(t, []byte("Chadland"), rm.KeysWithPrefix([]byte("Chad"), 0)[0])
+		})
+	}
+}
+
+func TestReadMostly_AlphabetTrie(t *testing.T) {
+	rm := concurrent.NewReadMostly(func() prefix_trees.Trie[string, int] {
+		return alphabet_trie.NewArray64[int](trietest.Alphabet)
+	}, nil)
+
+	_ = rm.Update(func(trie prefix_trees.Trie[string, int]) error {
+		trie.Put("alpha", 1)
+		return nil
+	})
+	_ = rm.Update(func(trie prefix_trees.Trie[string, int]) error {
+		trie.Put("beta", 2)
+		return nil
+	})
+
+	assert.Equal(t, 2, rm.Count())
+	assert.Equal(t, 1, rm.Get("alpha"))
+	assert.Equal(t, 2, rm.Get("beta"))
+}
+
+func TestReadMostly_Update_Error(t *testing.T) {
+	rm := concurrent.NewReadMostly(func() prefix_trees.Trie[[]byte, int] {
+		return &byte_trie.Array[int]{}
+	}, nil)
+	errUpdate := errors.New("update error")
+
+	err := rm.Update(func(trie prefix_trees.Trie[[]byte, int]) error {
+		trie.Put([]byte("alpha"), 1)
+		return errUpdate
+	})
+
+	assert.ErrorIs(t, err, errUpdate)
+	assert.Equal(t, 0, rm.Count())
+}
+
+func TestReadMostly_View_IsStable(t *testing.T) {
+	rm := concurrent.NewReadMostly(func() prefix_trees.Trie[[]byte, int] {
+		return &byte_trie.Array[int]{}
+	}, nil)
+	rm.Store(newFilledArray("alpha", "beta"))
+
+	err := rm.View(func(trie prefix_trees.Reader[[]byte, int]) error {
+		_ = rm.Update(func(trie prefix_trees.Trie[[]byte, int]) error {
+			trie.Delete([]byte("alpha"))
+			return nil
+		})
+
+		assert.Equal(t, 2, trie.Count())
+		assert.Equal(t, 1, trie.Get([]byte("alpha")))
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rm.Count())
+}
+
+func TestReadMostly_OnRelease(t *testing.T) {
+	var released []prefix_trees.Trie[[]byte, int]
+	rm := concurrent.NewReadMostly(func() prefix_trees.Trie[[]byte, int] {
+		return &byte_trie.Array[int]{}
+	}, func(trie prefix_trees.Trie[[]byte, int]) {
+		released = append(released, trie)
+	})
+	first := newFilledArray("alpha")
+	second := newFilledArray("beta")
+	rm.Store(first)
+
+	_ = rm.View(func(trie prefix_trees.Reader[[]byte, int]) error {
+		rm.Store(second)
+
+		// версия все еще используется читателем
+		assert.Len(t, released, 1)
+
+		return nil
+	})
+
+	if assert.Len(t, released, 2) {
+		assert.Same(t, first, released[1])
+	}
+	assert.Equal(t, 1, rm.Get([]byte("beta")))
+}
+
+// TestReadMostly_ConcurrentAccess проверяет одновременную работу читателей и писателей.
+// Тест предназначен для запуска с детектором гонок: go test -race.
+func TestReadMostly_ConcurrentAccess(t *testing.T) {
+	const readers = 8
+	const updates = 50
+	var released atomic.Int64
+	rm := concurrent.NewReadMostly(func() prefix_trees.Trie[[]byte, int] {
+		return &byte_trie.Array[int]{}
+	}, func(trie prefix_trees.Trie[[]byte, int]) {
+		released.Add(1)
+	})
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// в каждой версии все значения равны номеру версии
+				_ = rm.View(func(trie prefix_trees.Reader[[]byte, int]) error {
+					want, _ := trie.Find([]byte(fixtures.Countries[0]))
+					return trie.Walk(func(key []byte, value int) error {
+						if value != want {
+							t.Errorf("inconsistent version: %d != %d", value, want)
+						}
+						return nil
+					})
+				})
+			}
+		}()
+	}
+	for i := 1; i <= updates; i++ {
+		_ = rm.Update(func(trie prefix_trees.Trie[[]byte, int]) error {
+			for _, country := range fixtures.Countries {
+				trie.Put([]byte(country), i)
+			}
+			return nil
+		})
+	}
+	close(done)
+	wg.Wait()
+
+	assert.Equal(t, int64(updates), released.Load())
+	assert.Equal(t, updates, rm.Get([]byte(fixtures.Countries[0])))
+}
+
+func newFilledArray(keys ...string) *byte_trie.Array[int] {
+	array := &byte_trie.Array[int]{}
+	for i, key := range keys {
+		array.Put([]byte(key), i+1)
+	}
+
+	return array
+}
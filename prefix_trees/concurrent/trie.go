@@ -0,0 +1,169 @@
+// Package concurrent содержит потокобезопасные обертки над префиксными деревьями.
+package concurrent
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+// shardCount - количество шардов: отдельный шард для пустого ключа
+// и по одному шарду на каждое значение первого байта ключа.
+const shardCount = 257
+
+// Trie потокобезопасное префиксное дерево с байтовыми ключами.
+//
+// Корень дерева разделен на независимые шарды по первому байту ключа, каждый из которых
+// является отдельным деревом со своей блокировкой sync.RWMutex. Операции записи
+// блокируют только шард своего ключа, а операции чтения (Find, Walk) выполняются
+// параллельно под блокировкой на чтение. Поэтому операции с ключами, начинающимися
+// с разных байт, не мешают друг другу.
+//
+// Перебор дерева (Walk, WalkPrefix) блокирует шарды поочередно, поэтому он
+// не является атомарным снимком всего дерева. Функция перебора вызывается под
+// блокировкой шарда и не должна изменять дерево.
+type Trie[V any] struct {
+	shards [shardCount]shard[V]
+	count  atomic.Int64
+}
+
+type shard[V any] struct {
+	mu   sync.RWMutex
+	trie prefix_trees.Trie[[]byte, V]
+}
+
+// New создает потокобезопасное дерево, шарды которого создаются функцией newTrie.
+// Например, для шардов на основе byte_trie.Array:
+//
+//	trie := concurrent.New(func() prefix_trees.Trie[[]byte, int] {
+//		return &byte_trie.Array[int]{}
+//	})
+func New[V any](newTrie func() prefix_trees.Trie[[]byte, V]) *Trie[V] {
+	t := &Trie[V]{}
+	for i := range t.shards {
+		t.shards[i].trie = newTrie()
+	}
+
+	return t
+}
+
+func (t *Trie[V]) Count() int {
+	return int(t.count.Load())
+}
+
+func (t *Trie[V]) Get(key []byte) V {
+	v, _ := t.Find(key)
+
+	return v
+}
+
+func (t *Trie[V]) Find(key []byte) (V, bool) {
+	s := t.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.trie.Find(key)
+}
+
+func (t *Trie[V]) Put(key []byte, value V) {
+	s := t.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := s.trie.Count()
+	s.trie.Put(key, value)
+	t.count.Add(int64(s.trie.Count() - count))
+}
+
+func (t *Trie[V]) Delete(key []byte) {
+	s := t.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := s.trie.Count()
+	s.trie.Delete(key)
+	t.count.Add(int64(s.trie.Count() - count))
+}
+
+// Walk перебирает дерево в порядке возрастания ключей и для каждого существующего
+// значения вызывает функцию f.
+func (t *Trie[V]) Walk(f func(key []byte, value V) error) error {
+	for i := range t.shards {
+		if err := t.shards[i].walkPrefix(nil, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (t *Trie[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	if len(prefix) == 0 {
+		return t.Walk(f)
+	}
+
+	return t.shard(prefix).walkPrefix(prefix, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (t *Trie[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(t.WalkPrefix, prefix, limit)
+}
+
+func (t *Trie[V]) MarshalJSON() ([]byte, error) {
+	var data bytes.Buffer
+	if err := t.EncodeJSON(&data); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// UnmarshalJSON добавляет в дерево элементы объекта JSON. Существующие значения
+// с другими ключами сохраняются, как при декодировании в map.
+func (t *Trie[V]) UnmarshalJSON(data []byte) error {
+	return t.DecodeJSON(bytes.NewReader(data))
+}
+
+// EncodeJSON записывает дерево в w объектом JSON в порядке возрастания ключей.
+// Шарды блокируются на чтение поочередно, как при переборе методом Walk.
+// Для двоичных ключей следует использовать способ записи prefix_trees.KeyEscaped
+// или prefix_trees.KeyBase64.
+func (t *Trie[V]) EncodeJSON(w io.Writer, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Encode(w, t.Walk, options...)
+}
+
+// DecodeJSON читает объект JSON из r с помощью потокового декодера и добавляет
+// его элементы в дерево. Каждый элемент добавляется под блокировкой на запись
+// шарда своего ключа, поэтому другие горутины могут видеть частично прочитанный
+// документ. При ошибке в дереве остаются элементы, прочитанные до нее.
+func (t *Trie[V]) DecodeJSON(r io.Reader, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Decode(r, func(key []byte, value V) error {
+		t.Put(key, value)
+		return nil
+	}, options...)
+}
+
+// shard возвращает шард ключа: пустой ключ хранится в нулевом шарде,
+// остальные - в шарде с номером первого байта, увеличенным на единицу.
+func (t *Trie[V]) shard(key []byte) *shard[V] {
+	if len(key) == 0 {
+		return &t.shards[0]
+	}
+
+	return &t.shards[int(key[0])+1]
+}
+
+func (s *shard[V]) walkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.trie.WalkPrefix(prefix, f)
+}
@@ -0,0 +1,142 @@
+package concurrent_test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/byte_suffix_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/concurrent"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func newTrie() *concurrent.Trie[int] {
+	return concurrent.New(func() prefix_trees.Trie[[]byte, int] {
+		return &byte_trie.Array[int]{}
+	})
+}
+
+func TestTrie_Conformance(t *testing.T) {
+	trietest.Run(t, func() prefix_trees.Trie[[]byte, int] {
+		return newTrie()
+	})
+}
+
+func TestTrie_Conformance_SuffixShards(t *testing.T) {
+	trietest.Run(t, func() prefix_trees.Trie[[]byte, int] {
+		return concurrent.New(func() prefix_trees.Trie[[]byte, int] {
+			return &byte_suffix_trie.Array[int]{}
+		})
+	})
+}
+
+// TestTrie_ConcurrentAccess проверяет одновременную работу читателей и писателей.
+// Тест предназначен для запуска с детектором гонок: go test -race.
+func TestTrie_ConcurrentAccess(t *testing.T) {
+	const writers = 4
+	const readers = 8
+	const rounds = 200
+	trie := newTrie()
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				for i, country := range fixtures.Countries {
+					// каждый писатель работает со своими ключами, чтобы итоговое
+					// содержимое дерева было предсказуемым
+					key := []byte(fmt.Sprintf("%s %d", country, w))
+					if r%2 == 0 {
+						trie.Put(key, i)
+					} else {
+						trie.Delete(key)
+					}
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				country := fixtures.Countries[(r+i)%len(fixtures.Countries)]
+				trie.Find([]byte(country + " 0"))
+				trie.KeysWithPrefix([]byte(country[:1]), 10)
+				_ = trie.Walk(func(key []byte, value int) error {
+					return nil
+				})
+				trie.Count()
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	// последний раунд каждого писателя - удаление
+	assert.Equal(t, 0, trie.Count())
+	assert.Empty(t, trie.KeysWithPrefix(nil, 0))
+}
+
+func TestTrie_Walk_Ordered(t *testing.T) {
+	trie := newTrie()
+	for i, key := range []string{"b", "", "ab", "a", "\xff", "ba"} {
+		trie.Put([]byte(key), i)
+	}
+
+	keys := trie.KeysWithPrefix(nil, 0)
+
+	assert.Equal(t, [][]byte{nil, []byte("a"), []byte("ab"), []byte("b"), []byte("ba"), []byte("\xff")}, keys)
+}
+
+func TestTrie_EncodeJSON_BinaryKeys(t *testing.T) {
+	trie := newTrie()
+	keys := [][]byte{{0xFF, 0x00}, {0xC3}, []byte(`a\b`), []byte("кот")}
+	for i, key := range keys {
+		trie.Put(key, i)
+	}
+
+	var data bytes.Buffer
+	err := trie.EncodeJSON(&data, prefix_trees.WithKeyEncoding(prefix_trees.KeyEscaped))
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := newTrie()
+	err = restored.DecodeJSON(&data, prefix_trees.WithKeyEncoding(prefix_trees.KeyEscaped))
+
+	assert.NoError(t, err)
+	assert.Equal(t, trie.Count(), restored.Count())
+	for i, key := range keys {
+		assert.Equal(t, i, restored.Get(key), "at key: %v", key)
+	}
+}
+
+func BenchmarkTrie_ParallelFind(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	trie := newTrie()
+	for n, city := range cities {
+		trie.Put([]byte(city), n+1)
+	}
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			city := cities[i%len(cities)]
+			if _, found := trie.Find([]byte(city)); !found {
+				b.Fatal("element not found")
+			}
+			if i%100 == 0 {
+				trie.Put([]byte(city), i)
+			}
+			i++
+		}
+	})
+}
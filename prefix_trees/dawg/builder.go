@@ -0,0 +1,182 @@
+package dawg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsortedKeys - ключи для построения автомата не упорядочены по возрастанию
+// или повторяются.
+var ErrUnsortedKeys = errors.New("keys are not sorted")
+
+// Builder строит минимальный автомат инкрементально из упорядоченных ключей
+// (алгоритм Daciuk, Mihov, Watson, Watson).
+//
+// Последний добавленный ключ образует в автомате цепочку еще не проверенных
+// состояний. При добавлении следующего ключа состояния этой цепочки за общим
+// префиксом ключей больше не изменятся, поэтому они проверяются снизу вверх:
+// если в реестре уже есть эквивалентное состояние (с тем же признаком конца ключа
+// и теми же переходами), то состояние заменяется им, иначе добавляется в реестр.
+type Builder struct {
+	root     *state
+	register map[string]*state
+	// непроверенные состояния: переходы по байтам последнего ключа
+	unchecked []uncheckedState
+	previous  []byte
+	count     int
+	// количество состояний в реестре
+	states int
+	// буфер сигнатуры состояния
+	signature []byte
+}
+
+// state - состояние автомата во время построения.
+type state struct {
+	final bool
+	// переходы в порядке возрастания байт
+	edges []edge
+	// номер состояния в реестре
+	id int
+	// номер состояния в построенном автомате (-1 - не назначен)
+	index int32
+}
+
+type edge struct {
+	label byte
+	to    *state
+}
+
+type uncheckedState struct {
+	parent *state
+	child  *state
+}
+
+// NewBuilder создает построитель пустого автомата.
+func NewBuilder() *Builder {
+	return &Builder{
+		root:     &state{index: -1},
+		register: make(map[string]*state),
+	}
+}
+
+// Add добавляет ключ. Ключи должны добавляться в порядке возрастания байт
+// без повторов, иначе возвращается ошибка ErrUnsortedKeys. Ключ копируется.
+func (b *Builder) Add(key []byte) error {
+	if b.count > 0 && string(key) <= string(b.previous) {
+		return fmt.Errorf("%w: %q after %q", ErrUnsortedKeys, key, b.previous)
+	}
+
+	common := 0
+	for common < len(key) && common < len(b.previous) && key[common] == b.previous[common] {
+		common++
+	}
+	b.minimize(common)
+
+	node := b.root
+	if len(b.unchecked) > 0 {
+		node = b.unchecked[len(b.unchecked)-1].child
+	}
+	for _, k := range key[common:] {
+		child := &state{index: -1}
+		// ключи упорядочены, поэтому новый переход всегда последний
+		node.edges = append(node.edges, edge{label: k, to: child})
+		b.unchecked = append(b.unchecked, uncheckedState{parent: node, child: child})
+		node = child
+	}
+	node.final = true
+
+	b.previous = append(b.previous[:0], key...)
+	b.count++
+
+	return nil
+}
+
+// Finish завершает построение и возвращает автомат. После вызова построитель
+// использовать нельзя.
+func (b *Builder) Finish() *Set {
+	b.minimize(0)
+	set := freeze(b.root, b.count)
+	b.root, b.register, b.unchecked = nil, nil, nil
+
+	return set
+}
+
+// minimize проверяет непроверенные состояния глубже downTo, начиная с самого глубокого.
+func (b *Builder) minimize(downTo int) {
+	for i := len(b.unchecked) - 1; i >= downTo; i-- {
+		u := b.unchecked[i]
+		key := b.stateSignature(u.child)
+		if existing, ok := b.register[string(key)]; ok {
+			// эквивалентное состояние уже есть: переход родителя перенаправляется на него
+			u.parent.edges[len(u.parent.edges)-1].to = existing
+		} else {
+			u.child.id = b.states
+			b.states++
+			b.register[string(key)] = u.child
+		}
+	}
+	b.unchecked = b.unchecked[:downTo]
+}
+
+// stateSignature возвращает сигнатуру состояния: признак конца ключа и переходы
+// с номерами целевых состояний в реестре. Целевые состояния уже проверены.
+func (b *Builder) stateSignature(s *state) []byte {
+	key := b.signature[:0]
+	if s.final {
+		key = append(key, 1)
+	} else {
+		key = append(key, 0)
+	}
+	for _, e := range s.edges {
+		key = append(key, e.label)
+		key = binary.AppendUvarint(key, uint64(e.to.id))
+	}
+	b.signature = key
+
+	return key
+}
+
+// freeze переводит состояния в компактное представление: состояния нумеруются
+// в порядке обхода в глубину, переходы каждого состояния хранятся подряд.
+func freeze(root *state, count int) *Set {
+	set := &Set{count: count}
+
+	var states []*state
+	stack := []*state{root}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if s.index >= 0 {
+			// состояние достижимо несколькими путями и уже пронумеровано
+			continue
+		}
+		s.index = int32(len(states))
+		states = append(states, s)
+		// переходы добавляются в обратном порядке, чтобы обходить их по возрастанию байт
+		for i := len(s.edges) - 1; i >= 0; i-- {
+			if to := s.edges[i].to; to.index < 0 {
+				stack = append(stack, to)
+			}
+		}
+	}
+
+	set.offsets = make([]uint32, len(states)+1)
+	set.final = make([]uint64, (len(states)+63)/64)
+	for i, s := range states {
+		if s.final {
+			set.final[i/64] |= 1 << (i % 64)
+		}
+		set.offsets[i+1] = set.offsets[i] + uint32(len(s.edges))
+	}
+	set.labels = make([]byte, 0, set.offsets[len(states)])
+	set.targets = make([]uint32, 0, set.offsets[len(states)])
+	for _, s := range states {
+		for _, e := range s.edges {
+			set.labels = append(set.labels, e.label)
+			set.targets = append(set.targets, uint32(e.to.index))
+		}
+	}
+
+	return set
+}
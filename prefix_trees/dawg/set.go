@@ -0,0 +1,146 @@
+// Package dawg содержит минимальный детерминированный ациклический автомат
+// (DAWG, directed acyclic word graph) для хранения множества ключей.
+//
+// В отличие от префиксного дерева автомат объединяет не только общие префиксы,
+// но и общие суффиксы ключей: эквивалентные поддеревья (с одинаковыми наборами
+// окончаний ключей) хранятся один раз. Автомат строится инкрементально из
+// упорядоченных ключей и после построения не изменяется. Значения с ключами
+// не связываются, так как у разных ключей могут совпадать все состояния пути.
+package dawg
+
+import (
+	"sort"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// Set неизменяемое множество ключей в виде минимального автомата.
+//
+// Состояния нумеруются с нуля (0 - начальное состояние). Переходы состояния s
+// хранятся подряд в диапазоне [offsets[s], offsets[s+1]) массивов labels и targets
+// в порядке возрастания байт. На переход приходится 5 байт, на состояние - 4 байта
+// и бит признака конца ключа. Множество безопасно для одновременного чтения
+// из нескольких горутин.
+type Set struct {
+	offsets []uint32
+	labels  []byte
+	targets []uint32
+	// признаки конца ключа в состояниях
+	final []uint64
+	count int
+}
+
+// NewFromSorted строит множество из ключей, упорядоченных по возрастанию байт.
+// Если ключи не упорядочены или повторяются, возвращается ошибка ErrUnsortedKeys.
+func NewFromSorted[K prefix_trees.Key](keys []K) (*Set, error) {
+	b := NewBuilder()
+	for _, key := range keys {
+		if err := b.Add([]byte(key)); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Finish(), nil
+}
+
+// Count возвращает количество ключей.
+func (set *Set) Count() int {
+	return set.count
+}
+
+// States возвращает количество состояний автомата.
+func (set *Set) States() int {
+	return len(set.offsets) - 1
+}
+
+// Transitions возвращает количество переходов автомата.
+func (set *Set) Transitions() int {
+	return len(set.labels)
+}
+
+// Size возвращает размер массивов состояний и переходов автомата в байтах.
+func (set *Set) Size() int {
+	return 4*len(set.offsets) + len(set.labels) + 4*len(set.targets) + 8*len(set.final)
+}
+
+// Contains проверяет наличие ключа в множестве.
+func (set *Set) Contains(key []byte) bool {
+	s, ok := set.descend(key)
+
+	return ok && set.isFinal(s)
+}
+
+// Walk перебирает ключи в порядке возрастания и для каждого из них вызывает
+// функцию f. Слайс ключа используется повторно и должен быть скопирован,
+// если он нужен после возврата из f.
+func (set *Set) Walk(f func(key []byte) error) error {
+	return set.WalkPrefix(nil, f)
+}
+
+// WalkPrefix перебирает в порядке возрастания ключи, начинающиеся с префикса
+// prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (set *Set) WalkPrefix(prefix []byte, f func(key []byte) error) error {
+	s, ok := set.descend(prefix)
+	if !ok {
+		return nil
+	}
+
+	key := make([]byte, len(prefix), len(prefix)+32)
+	copy(key, prefix)
+
+	return set.walk(s, key, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (set *Set) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	walkPrefix := func(prefix []byte, f func(key []byte, _ struct{}) error) error {
+		return set.WalkPrefix(prefix, func(key []byte) error {
+			return f(key, struct{}{})
+		})
+	}
+
+	return prefix_trees.KeysWithPrefix(walkPrefix, prefix, limit)
+}
+
+// descend возвращает состояние, в которое автомат переходит по ключу key.
+func (set *Set) descend(key []byte) (uint32, bool) {
+	if len(set.offsets) == 0 {
+		return 0, false
+	}
+
+	s := uint32(0)
+	for _, k := range key {
+		lo, hi := int(set.offsets[s]), int(set.offsets[s+1])
+		// переходы упорядочены по байту, поэтому используется двоичный поиск
+		i := lo + sort.Search(hi-lo, func(i int) bool {
+			return set.labels[lo+i] >= k
+		})
+		if i == hi || set.labels[i] != k {
+			return 0, false
+		}
+		s = set.targets[i]
+	}
+
+	return s, true
+}
+
+func (set *Set) isFinal(s uint32) bool {
+	return set.final[s/64]&(1<<(s%64)) != 0
+}
+
+func (set *Set) walk(s uint32, key []byte, f func(key []byte) error) error {
+	if set.isFinal(s) {
+		if err := f(key); err != nil {
+			return err
+		}
+	}
+
+	for i := set.offsets[s]; i < set.offsets[s+1]; i++ {
+		if err := set.walk(set.targets[i], append(key, set.labels[i]), f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
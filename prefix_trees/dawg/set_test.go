@@ -0,0 +1,273 @@
+package dawg_test
+
+import (
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/dawg"
+	"github.com/strider2038/algos/prefix_trees/louds_trie"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestNewFromSorted(t *testing.T) {
+	keys := sortedCountries()
+
+	set, err := dawg.NewFromSorted(keys)
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(keys), set.Count())
+	for _, key := range keys {
+		assert.True(t, set.Contains([]byte(key)), "at key: %s", key)
+	}
+	for _, key := range []string{"", "A", "Bosnia", "Chadd", "Zz", "q"} {
+		assert.False(t, set.Contains([]byte(key)), "at key: %s", key)
+	}
+	assertWalkOrdered(t, keys, set)
+}
+
+func TestNewFromSorted_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+	}{
+		{name: "unsorted", keys: []string{"b", "a"}},
+		{name: "duplicate", keys: []string{"a", "a"}},
+		{name: "prefix after key", keys: []string{"ab", "a"}},
+		{name: "duplicate empty key", keys: []string{"", ""}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := dawg.NewFromSorted(test.keys)
+
+			assert.ErrorIs(t, err, dawg.ErrUnsortedKeys)
+		})
+	}
+}
+
+func TestSet_Minimal(t *testing.T) {
+	// общие окончания "p" и "ps" хранятся один раз
+	set, err := dawg.NewFromSorted([]string{"tap", "taps", "top", "tops"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, set.States())
+	assert.Equal(t, 5, set.Transitions())
+	// 6 смещений, 5 меток, 5 целевых состояний и одно слово признаков конца ключа
+	assert.Equal(t, 6*4+5+5*4+8, set.Size())
+	assert.Equal(t, []string{"tap", "taps", "top", "tops"}, keysOf(set.KeysWithPrefix(nil, 0)))
+}
+
+func TestSet_EmptyKey(t *testing.T) {
+	set, err := dawg.NewFromSorted([]string{"", "a"})
+
+	assert.NoError(t, err)
+	assert.True(t, set.Contains(nil))
+	assert.True(t, set.Contains([]byte("a")))
+	assert.Equal(t, []string{"", "a"}, keysOf(set.KeysWithPrefix(nil, 0)))
+}
+
+func TestSet_Empty(t *testing.T) {
+	set, err := dawg.NewFromSorted[string](nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, set.Count())
+	assert.False(t, set.Contains(nil))
+	assert.Empty(t, set.KeysWithPrefix(nil, 0))
+}
+
+func TestSet_WalkPrefix(t *testing.T) {
+	keys := sortedCountries()
+	set, err := dawg.NewFromSorted(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes := []string{"", "B", "Bo", "Bosnia", "Bosnia and Herzegovina", "Bosnia and Herzegovina!", "Z", "q"}
+	for _, prefix := range prefixes {
+		var want []string
+		for _, key := range keys {
+			if len(key) >= len(prefix) && key[:len(prefix)] == prefix {
+				want = append(want, key)
+			}
+		}
+		assert.Equal(t, want, keysOf(set.KeysWithPrefix([]byte(prefix), 0)), "at prefix: %s", prefix)
+	}
+	assert.Len(t, set.KeysWithPrefix([]byte("B"), 3), 3)
+}
+
+func TestSet_RandomKeys(t *testing.T) {
+	unique := map[string]bool{}
+	for i := 0; i < 10_000; i++ {
+		key := make([]byte, rand.Intn(8))
+		for j := range key {
+			key[j] = "abc\x00\xff"[rand.Intn(5)]
+		}
+		unique[string(key)] = true
+	}
+	keys := make([]string, 0, len(unique))
+	for key := range unique {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	set, err := dawg.NewFromSorted(keys)
+
+	assert.NoError(t, err)
+	assertWalkOrdered(t, keys, set)
+	for i := 0; i < 1000; i++ {
+		key := make([]byte, rand.Intn(9))
+		for j := range key {
+			key[j] = "abcd\x00\xff"[rand.Intn(6)]
+		}
+		assert.Equal(t, unique[string(key)], set.Contains(key), "at key: %q", key)
+	}
+}
+
+func BenchmarkSet_Build(b *testing.B) {
+	cities := sortedCities(b)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	var set *dawg.Set
+	for i := 0; i < b.N; i++ {
+		var err error
+		if set, err = dawg.NewFromSorted(cities); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(set.Size()), "bytes")
+}
+
+func BenchmarkSet_Contains(b *testing.B) {
+	set, err := dawg.NewFromSorted(sortedCities(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	benchmarks := []struct {
+		name     string
+		cityName string
+	}{
+		{
+			name:     "short name",
+			cityName: "Adville",
+		},
+		{
+			name:     "long name",
+			cityName: "Advocate Lutheran General Childrens Hospital",
+		},
+		{
+			name:     "long unique suffix",
+			cityName: "Advocate Services Medical Transportation",
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !set.Contains([]byte(bm.cityName)) {
+					b.Fatal("element not found")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSet_Memory измеряет объем кучи, занятой множеством названий городов,
+// в сравнении со сжатым префиксным деревом louds_trie.
+func BenchmarkSet_Memory(b *testing.B) {
+	cities := sortedCities(b)
+
+	implementations := []struct {
+		name  string
+		build func() (any, error)
+	}{
+		{
+			name:  "dawg",
+			build: func() (any, error) { return dawg.NewFromSorted(cities) },
+		},
+		{
+			name: "louds trie",
+			build: func() (any, error) {
+				return louds_trie.NewFromSorted(cities, make([]struct{}, len(cities)))
+			},
+		},
+	}
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			var heap int64
+			for i := 0; i < b.N; i++ {
+				before := heapInUse()
+				set, err := impl.build()
+				if err != nil {
+					b.Fatal(err)
+				}
+				heap = heapInUse() - before
+				runtime.KeepAlive(set)
+			}
+			b.ReportMetric(float64(heap), "heap-bytes")
+			b.ReportMetric(float64(heap)/float64(len(cities)), "bytes/key")
+		})
+	}
+}
+
+// sortedCountries возвращает упорядоченные названия стран без повторов.
+func sortedCountries() []string {
+	keys := append([]string(nil), fixtures.Countries...)
+	sort.Strings(keys)
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			unique = append(unique, key)
+		}
+	}
+
+	return unique
+}
+
+// sortedCities возвращает упорядоченные названия городов без повторов.
+func sortedCities(b *testing.B) []string {
+	cities := fixtures.CitiesT(b)
+	sort.Strings(cities)
+	unique := cities[:0]
+	for i, city := range cities {
+		if i == 0 || city != cities[i-1] {
+			unique = append(unique, city)
+		}
+	}
+
+	return unique
+}
+
+func keysOf(keys [][]byte) []string {
+	var result []string
+	for _, key := range keys {
+		result = append(result, string(key))
+	}
+
+	return result
+}
+
+func assertWalkOrdered(t *testing.T, keys []string, set *dawg.Set) {
+	t.Helper()
+
+	var got []string
+	err := set.Walk(func(key []byte) error {
+		got = append(got, string(key))
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, keys, got)
+}
+
+func heapInUse() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return int64(stats.HeapAlloc)
+}
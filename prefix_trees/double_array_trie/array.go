@@ -0,0 +1,428 @@
+// Package double_array_trie содержит префиксное дерево на основе двойного массива
+// (double-array trie, J. Aoe) с динамической вставкой и удалением ключей.
+package double_array_trie
+
+import (
+	"bytes"
+	"io"
+	"unsafe"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+const (
+	// root - номер ячейки корневого состояния
+	root = 1
+	// terminal - код перехода, завершающего ключ. Байт b ключа имеет код b+1.
+	terminal = 0
+	// codes - количество кодов переходов
+	codes = 257
+	// maxTrials - количество свободных ячеек, проверяемых при поиске base
+	maxTrials = 512
+)
+
+// Array префиксное дерево на основе двойного массива.
+//
+// Состояния дерева хранятся в двух массивах: переход из состояния s по коду c ведет
+// в ячейку t = base[s] + c и существует, если check[t] == s. Таким образом переход
+// выполняется за O(1) без вычисления индекса по битовой маске. При вставке нового
+// перехода, ячейка которого занята другим состоянием, дочерние состояния
+// перемещаются на новое значение base.
+//
+// Переход по коду terminal завершает ключ, в его ячейке base хранит номер значения.
+// Свободные ячейки образуют двусвязный список: в check хранится следующая свободная
+// ячейка, в base - предыдущая (в виде -n-1, чтобы отличать их от занятых ячеек).
+// Ячейка 0 является заголовком списка.
+//
+// Нулевое значение является пустым деревом.
+type Array[V any] struct {
+	base  []int32
+	check []int32
+	// значения и номера освободившихся значений
+	values     []V
+	freeValues []int32
+	count      int
+}
+
+func (array *Array[V]) Count() int {
+	return array.count
+}
+
+// Size возвращает размер массивов дерева в байтах: ячеек BASE/CHECK, включая
+// незанятые, и значений (без памяти, на которую ссылаются сами значения).
+func (array *Array[V]) Size() int {
+	var zero V
+
+	return 4*(len(array.base)+len(array.check)+len(array.freeValues)) +
+		int(unsafe.Sizeof(zero))*len(array.values)
+}
+
+func (array *Array[V]) Get(key []byte) V {
+	v, _ := array.Find(key)
+
+	return v
+}
+
+func (array *Array[V]) Find(key []byte) (V, bool) {
+	var zero V
+	if len(array.check) == 0 {
+		return zero, false
+	}
+
+	s := int32(root)
+	for _, k := range key {
+		t := array.base[s] + int32(k) + 1
+		if int(t) >= len(array.check) || array.check[t] != s {
+			return zero, false
+		}
+		s = t
+	}
+
+	t := array.base[s] + terminal
+	if int(t) >= len(array.check) || array.check[t] != s {
+		return zero, false
+	}
+
+	return array.values[array.base[t]], true
+}
+
+func (array *Array[V]) Put(key []byte, value V) {
+	if len(array.check) == 0 {
+		array.init()
+	}
+
+	s := int32(root)
+	for _, k := range key {
+		s = array.transition(s, int32(k)+1)
+	}
+
+	t := array.base[s] + terminal
+	if int(t) < len(array.check) && array.check[t] == s {
+		array.values[array.base[t]] = value
+		return
+	}
+
+	t = array.transition(s, terminal)
+	array.base[t] = array.addValue(value)
+	array.count++
+}
+
+// Delete удаляет значение из ассоциативного массива. Состояния, у которых
+// не осталось переходов, освобождаются.
+func (array *Array[V]) Delete(key []byte) {
+	if len(array.check) == 0 {
+		return
+	}
+
+	s := int32(root)
+	for _, k := range key {
+		t := array.base[s] + int32(k) + 1
+		if int(t) >= len(array.check) || array.check[t] != s {
+			return
+		}
+		s = t
+	}
+
+	t := array.base[s] + terminal
+	if int(t) >= len(array.check) || array.check[t] != s {
+		return
+	}
+
+	var zero V
+	array.values[array.base[t]] = zero
+	array.freeValues = append(array.freeValues, array.base[t])
+	array.freeCell(t)
+	array.count--
+
+	// освобождаем опустевшие состояния на пути к корню
+	for s != root && !array.hasChildren(s) {
+		parent := array.check[s]
+		array.freeCell(s)
+		s = parent
+	}
+}
+
+// Walk перебирает дерево в порядке возрастания ключей и для каждого существующего
+// значения вызывает функцию f.
+func (array *Array[V]) Walk(f func(key []byte, value V) error) error {
+	return array.WalkPrefix(nil, f)
+}
+
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (array *Array[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	if len(array.check) == 0 {
+		return nil
+	}
+
+	s := int32(root)
+	for _, k := range prefix {
+		t := array.base[s] + int32(k) + 1
+		if int(t) >= len(array.check) || array.check[t] != s {
+			return nil
+		}
+		s = t
+	}
+
+	key := make([]byte, len(prefix), len(prefix)+32)
+	copy(key, prefix)
+
+	return array.walk(s, key, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (array *Array[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(array.WalkPrefix, prefix, limit)
+}
+
+func (array *Array[V]) MarshalJSON() ([]byte, error) {
+	var data bytes.Buffer
+	if err := array.EncodeJSON(&data); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// EncodeJSON записывает дерево в w объектом JSON в порядке возрастания ключей.
+func (array *Array[V]) EncodeJSON(w io.Writer, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Encode(w, array.Walk, options...)
+}
+
+func (array *Array[V]) walk(s int32, key []byte, f func(key []byte, value V) error) error {
+	// переход terminal имеет наименьший код, поэтому значение узла
+	// перебирается раньше дочерних ключей
+	for c := int32(0); c < codes; c++ {
+		t := array.base[s] + c
+		if int(t) >= len(array.check) {
+			break
+		}
+		if array.check[t] != s {
+			continue
+		}
+
+		if c == terminal {
+			if err := f(key, array.values[array.base[t]]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := array.walk(t, append(key, byte(c-1)), f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// init создает корневое состояние.
+func (array *Array[V]) init() {
+	array.base = []int32{-1, 0}
+	array.check = []int32{-1, 0}
+	array.grow(256)
+}
+
+// transition возвращает ячейку перехода из состояния s по коду c, создавая
+// переход при необходимости.
+func (array *Array[V]) transition(s, c int32) int32 {
+	t := array.base[s] + c
+	if int(t) < len(array.check) && array.check[t] == s {
+		return t
+	}
+
+	if array.base[s] == 0 {
+		// у состояния еще нет переходов
+		array.base[s] = array.findBase([]int32{c})
+	} else if array.ensure(t); array.check[t] >= 0 {
+		// ячейка занята другим состоянием: перемещаем переходы s
+		array.relocate(s, append(array.children(s), c))
+	}
+
+	t = array.base[s] + c
+	array.ensure(t)
+	array.allocCell(t)
+	array.check[t] = s
+	array.base[t] = 0
+
+	return t
+}
+
+// relocate перемещает переходы состояния s на новое значение base, при котором
+// свободны ячейки всех кодов codes (включая новый код перехода).
+func (array *Array[V]) relocate(s int32, codes []int32) {
+	oldBase := array.base[s]
+	newBase := array.findBase(codes)
+
+	// последний код - новый переход, для него ячейка будет занята вызывающей стороной
+	moved := codes[:len(codes)-1]
+	for _, c := range moved {
+		array.ensure(newBase + c)
+		array.allocCell(newBase + c)
+	}
+
+	for _, c := range moved {
+		from, to := oldBase+c, newBase+c
+		array.base[to] = array.base[from]
+		array.check[to] = s
+		// дочерние состояния перемещенного состояния ссылаются на новую ячейку
+		if c != terminal && array.base[from] != 0 {
+			for _, grandchild := range array.children(from) {
+				array.check[array.base[from]+grandchild] = to
+			}
+		}
+	}
+
+	for _, c := range moved {
+		array.freeCell(oldBase + c)
+	}
+	array.base[s] = newBase
+}
+
+// findBase находит значение base, при котором свободны ячейки всех кодов codes.
+// Проверяется не более maxTrials свободных ячеек из начала списка, чтобы поиск
+// не становился линейным от размера массивов. Если подходящей ячейки не нашлось,
+// переходы размещаются за концом массивов.
+func (array *Array[V]) findBase(codes []int32) int32 {
+	first := codes[0]
+	for _, c := range codes {
+		if c < first {
+			first = c
+		}
+	}
+
+	f := -array.check[0] - 1
+	for trial := 0; trial < maxTrials && f != 0; trial++ {
+		next := -array.check[f] - 1
+		if b := f - first; b >= 1 && array.isFree(b, codes) {
+			return b
+		}
+		// неподошедшая ячейка переносится в конец списка, чтобы следующие поиски
+		// начинались с других ячеек
+		array.allocCell(f)
+		array.freeCell(f)
+		f = next
+	}
+
+	b := int32(len(array.check)) - first
+	if b < 1 {
+		b = 1
+	}
+
+	return b
+}
+
+// isFree проверяет, что ячейки base+c для всех кодов свободны или находятся
+// за концом массивов.
+func (array *Array[V]) isFree(base int32, codes []int32) bool {
+	for _, c := range codes {
+		t := base + c
+		if int(t) < len(array.check) && array.check[t] >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// children возвращает коды переходов состояния s.
+func (array *Array[V]) children(s int32) []int32 {
+	var result []int32
+	for c := int32(0); c < codes; c++ {
+		t := array.base[s] + c
+		if int(t) >= len(array.check) {
+			break
+		}
+		if array.check[t] == s {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+func (array *Array[V]) hasChildren(s int32) bool {
+	if array.base[s] == 0 {
+		return false
+	}
+	for c := int32(0); c < codes; c++ {
+		t := array.base[s] + c
+		if int(t) >= len(array.check) {
+			break
+		}
+		if array.check[t] == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ensure расширяет массивы так, чтобы ячейка t существовала.
+func (array *Array[V]) ensure(t int32) {
+	if int(t) >= len(array.check) {
+		array.grow(int(t) + 1)
+	}
+}
+
+// grow расширяет массивы как минимум до size ячеек и добавляет новые ячейки
+// в список свободных.
+func (array *Array[V]) grow(size int) {
+	n := len(array.check)
+	if size < 2*n {
+		size = 2 * n
+	}
+
+	base := make([]int32, size)
+	copy(base, array.base)
+	check := make([]int32, size)
+	copy(check, array.check)
+	array.base, array.check = base, check
+
+	// новые ячейки добавляются в начало списка, чтобы поиск base проверял их
+	// раньше разрозненных освободившихся ячеек
+	for i := size - 1; i >= n; i-- {
+		array.pushFreeCell(int32(i))
+	}
+}
+
+// allocCell исключает ячейку t из списка свободных.
+func (array *Array[V]) allocCell(t int32) {
+	prev, next := -array.base[t]-1, -array.check[t]-1
+	array.check[prev] = -next - 1
+	array.base[next] = -prev - 1
+}
+
+// freeCell добавляет ячейку t в конец списка свободных.
+func (array *Array[V]) freeCell(t int32) {
+	last := -array.base[0] - 1
+	array.check[last] = -t - 1
+	array.base[t] = -last - 1
+	array.check[t] = -1
+	array.base[0] = -t - 1
+}
+
+// pushFreeCell добавляет ячейку t в начало списка свободных.
+func (array *Array[V]) pushFreeCell(t int32) {
+	first := -array.check[0] - 1
+	array.base[first] = -t - 1
+	array.check[t] = -first - 1
+	array.base[t] = -1
+	array.check[0] = -t - 1
+}
+
+// addValue сохраняет значение и возвращает его номер.
+func (array *Array[V]) addValue(value V) int32 {
+	if n := len(array.freeValues); n > 0 {
+		index := array.freeValues[n-1]
+		array.freeValues = array.freeValues[:n-1]
+		array.values[index] = value
+
+		return index
+	}
+
+	array.values = append(array.values, value)
+
+	return int32(len(array.values) - 1)
+}
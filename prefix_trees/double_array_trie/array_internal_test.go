@@ -0,0 +1,84 @@
+package double_array_trie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArray_Delete_FreesCells(t *testing.T) {
+	items := Array[int]{}
+	keys := make([][]byte, 0, 2000)
+	for i := 0; i < cap(keys); i++ {
+		key := make([]byte, 1+rand.Intn(6))
+		rand.Read(key)
+		keys = append(keys, key)
+		items.Put(key, i)
+	}
+	assertFreeList(t, &items)
+
+	for _, key := range keys {
+		items.Delete(key)
+	}
+
+	assert.Equal(t, 0, items.Count())
+	assertFreeList(t, &items)
+	// занятой остается только ячейка корня
+	used := 0
+	for i := 1; i < len(items.check); i++ {
+		if items.check[i] >= 0 {
+			used++
+		}
+	}
+	assert.Equal(t, 1, used)
+	assert.Len(t, items.freeValues, len(items.values))
+}
+
+func TestArray_Put_RelocatesChildren(t *testing.T) {
+	items := Array[int]{}
+	// ключи с общими префиксами вынуждают перемещать переходы уже созданных состояний
+	for i := 0; i < 256; i++ {
+		items.Put([]byte{byte(i)}, i)
+		items.Put([]byte{byte(i), byte(255 - i)}, -i)
+	}
+	assertFreeList(t, &items)
+
+	for i := 0; i < 256; i++ {
+		assert.Equal(t, i, items.Get([]byte{byte(i)}))
+		assert.Equal(t, -i, items.Get([]byte{byte(i), byte(255 - i)}))
+	}
+}
+
+// assertFreeList проверяет, что список свободных ячеек содержит ровно
+// все свободные ячейки и ссылки в обе стороны согласованы.
+func assertFreeList(t *testing.T, array *Array[int]) {
+	t.Helper()
+
+	free := 0
+	for i := 1; i < len(array.check); i++ {
+		if array.check[i] < 0 {
+			free++
+		}
+	}
+
+	listed := 0
+	for prev, f := int32(0), -array.check[0]-1; f != 0; prev, f = f, -array.check[f]-1 {
+		if !assert.Equal(t, prev, -array.base[f]-1, "at cell %d", f) {
+			return
+		}
+		listed++
+	}
+	assert.Equal(t, free, listed)
+}
+
+func TestArray_Size(t *testing.T) {
+	items := Array[int64]{}
+	assert.Equal(t, 0, items.Size())
+
+	items.Put([]byte("a"), 1)
+	items.Put([]byte("b"), 2)
+	items.Delete([]byte("a"))
+
+	assert.Equal(t, 4*(len(items.base)+len(items.check))+4*1+8*2, items.Size())
+}
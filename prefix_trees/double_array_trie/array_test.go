@@ -0,0 +1,129 @@
+package double_array_trie_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/byte_shard_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/double_array_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestArray_Conformance(t *testing.T) {
+	trietest.Run(t, func() prefix_trees.Trie[[]byte, int] {
+		return &double_array_trie.Array[int]{}
+	})
+}
+
+// implementations - деревья, с которыми сравнивается двойной массив
+// на одной и той же нагрузке.
+var implementations = []struct {
+	name    string
+	newTrie func() prefix_trees.Trie[[]byte, int]
+}{
+	{
+		name:    "double array",
+		newTrie: func() prefix_trees.Trie[[]byte, int] { return &double_array_trie.Array[int]{} },
+	},
+	{
+		name:    "byte trie",
+		newTrie: func() prefix_trees.Trie[[]byte, int] { return &byte_trie.Array[int]{} },
+	},
+	{
+		name:    "byte shard trie",
+		newTrie: func() prefix_trees.Trie[[]byte, int] { return &byte_shard_trie.Array[int]{} },
+	},
+}
+
+func BenchmarkArray_Fill(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				t := impl.newTrie()
+				for n, city := range cities {
+					t.Put([]byte(city), n+1)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkArray_Get(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+
+	benchmarks := []struct {
+		name     string
+		cityName string
+	}{
+		{
+			name:     "short name",
+			cityName: "Adville",
+		},
+		{
+			name:     "long name",
+			cityName: "Advocate Lutheran General Childrens Hospital",
+		},
+		{
+			name:     "long unique suffix",
+			cityName: "Advocate Services Medical Transportation",
+		},
+	}
+	for _, impl := range implementations {
+		t := impl.newTrie()
+		for n, city := range cities {
+			t.Put([]byte(city), n+1)
+		}
+
+		for _, bm := range benchmarks {
+			b.Run(impl.name+"/"+bm.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_, found := t.Find([]byte(bm.cityName))
+					if !found {
+						b.Fatal("element not found")
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkArray_Memory измеряет объем кучи, занятой деревом с названиями городов.
+func BenchmarkArray_Memory(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			var heap int64
+			var t prefix_trees.Trie[[]byte, int]
+			for i := 0; i < b.N; i++ {
+				before := heapInUse()
+				t = impl.newTrie()
+				for n, city := range cities {
+					t.Put([]byte(city), n+1)
+				}
+				heap = heapInUse() - before
+				runtime.KeepAlive(t)
+			}
+			b.ReportMetric(float64(heap), "heap-bytes")
+			b.ReportMetric(float64(heap)/float64(len(cities)), "bytes/key")
+			// размер массивов BASE/CHECK и значений без учета запаса емкости слайсов
+			if sized, ok := t.(interface{ Size() int }); ok {
+				b.ReportMetric(float64(sized.Size()), "bytes")
+			}
+		})
+	}
+}
+
+func heapInUse() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return int64(stats.HeapAlloc)
+}
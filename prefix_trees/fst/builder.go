@@ -0,0 +1,239 @@
+package fst
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsortedKeys - ключи для построения не упорядочены по возрастанию или повторяются.
+var ErrUnsortedKeys = errors.New("keys are not sorted")
+
+// флаги состояния
+const (
+	flagFinal       = 1
+	flagFinalOutput = 2
+)
+
+// Builder строит минимальный преобразователь инкрементально из упорядоченных пар
+// ключ-выход (алгоритм Mihov, Maurel).
+//
+// Состояния пути последнего добавленного ключа еще могут измениться и хранятся
+// отдельно. При добавлении следующего ключа состояния за общим префиксом ключей
+// компилируются снизу вверх: если эквивалентное состояние (с теми же признаком
+// конца ключа, выходом и переходами) уже записано, то используется его адрес.
+//
+// Выход ключа распределяется по переходам: на общем префиксе с предыдущим ключом
+// переход сохраняет минимум выходов, а разница переносится на переходы следующего
+// состояния. Поэтому выход ключа - сумма выходов переходов пути и выхода конечного
+// состояния.
+type Builder struct {
+	// состояния пути последнего ключа: nodes[i] - состояние после i байт
+	nodes    []*builderNode
+	previous []byte
+	count    int
+	data     []byte
+	// адреса записанных состояний по их сигнатурам
+	register  map[string]uint32
+	signature []byte
+}
+
+type builderNode struct {
+	final       bool
+	finalOutput uint64
+	edges       []builderEdge
+}
+
+type builderEdge struct {
+	label  byte
+	output uint64
+	// адрес целевого состояния (назначается при его компиляции)
+	target uint32
+}
+
+// NewBuilder создает построитель пустого преобразователя.
+func NewBuilder() *Builder {
+	return &Builder{
+		nodes:    []*builderNode{{}},
+		register: make(map[string]uint32),
+	}
+}
+
+// Add добавляет ключ с выходом output. Ключи должны добавляться в порядке
+// возрастания байт без повторов, иначе возвращается ошибка ErrUnsortedKeys.
+func (b *Builder) Add(key []byte, output uint64) error {
+	if b.count > 0 && string(key) <= string(b.previous) {
+		return fmt.Errorf("%w: %q after %q", ErrUnsortedKeys, key, b.previous)
+	}
+
+	common := 0
+	for common < len(key) && common < len(b.previous) && key[common] == b.previous[common] {
+		common++
+	}
+	b.compileFrom(common)
+
+	for i := common + 1; i <= len(key); i++ {
+		if i < len(b.nodes) {
+			*b.nodes[i] = builderNode{edges: b.nodes[i].edges[:0]}
+		} else {
+			b.nodes = append(b.nodes, &builderNode{})
+		}
+	}
+
+	// выход переносится с общего префикса на новые переходы
+	for i := 0; i < common; i++ {
+		edge := &b.nodes[i].edges[len(b.nodes[i].edges)-1]
+		shared := edge.output
+		if output < shared {
+			shared = output
+		}
+		if rest := edge.output - shared; rest > 0 {
+			b.nodes[i+1].prepend(rest)
+		}
+		edge.output = shared
+		output -= shared
+	}
+
+	if common == len(key) {
+		// только пустой первый ключ
+		b.nodes[common].final = true
+		b.nodes[common].finalOutput = output
+	} else {
+		// остаток выхода хранится на первом новом переходе, остальные новые переходы без выхода
+		b.nodes[common].edges = append(b.nodes[common].edges, builderEdge{label: key[common], output: output})
+		for i := common + 1; i < len(key); i++ {
+			b.nodes[i].edges = append(b.nodes[i].edges, builderEdge{label: key[i]})
+		}
+		b.nodes[len(key)].final = true
+	}
+
+	b.previous = append(b.previous[:0], key...)
+	b.count++
+
+	return nil
+}
+
+// Finish завершает построение и возвращает преобразователь. После вызова
+// построитель использовать нельзя.
+func (b *Builder) Finish() *Map {
+	b.compileFrom(0)
+	root := b.compile(b.nodes[0])
+	m := &Map{data: b.data, root: root, count: b.count}
+	b.nodes, b.register, b.data = nil, nil, nil
+
+	return m
+}
+
+// compileFrom компилирует состояния пути предыдущего ключа глубже depth.
+func (b *Builder) compileFrom(depth int) {
+	for i := len(b.previous); i > depth; i-- {
+		parent := b.nodes[i-1]
+		parent.edges[len(parent.edges)-1].target = b.compile(b.nodes[i])
+	}
+}
+
+// compile записывает состояние и возвращает его адрес. Если эквивалентное
+// состояние уже записано, то возвращается его адрес.
+//
+// Формат состояния:
+//
+//	flags        1 байт
+//	n            uvarint, количество переходов
+//	widths       1 байт: ширина смещений целевых состояний (младшие 4 бита) и выходов
+//	final output uvarint (если установлен флаг flagFinalOutput)
+//	labels       n байт в порядке возрастания
+//	targets      n смещений фиксированной ширины: адрес состояния минус адрес цели
+//	outputs      n выходов фиксированной ширины
+func (b *Builder) compile(node *builderNode) uint32 {
+	signature := b.nodeSignature(node)
+	if address, ok := b.register[string(signature)]; ok {
+		return address
+	}
+
+	address := uint32(len(b.data))
+	var flags byte
+	if node.final {
+		flags |= flagFinal
+	}
+	if node.finalOutput != 0 {
+		flags |= flagFinalOutput
+	}
+
+	var maxDelta, maxOutput uint64
+	for _, edge := range node.edges {
+		if delta := uint64(address - edge.target); delta > maxDelta {
+			maxDelta = delta
+		}
+		if edge.output > maxOutput {
+			maxOutput = edge.output
+		}
+	}
+	targetWidth, outputWidth := width(maxDelta), width(maxOutput)
+
+	data := append(b.data, flags)
+	data = binary.AppendUvarint(data, uint64(len(node.edges)))
+	data = append(data, byte(targetWidth|outputWidth<<4))
+	if node.finalOutput != 0 {
+		data = binary.AppendUvarint(data, node.finalOutput)
+	}
+	for _, edge := range node.edges {
+		data = append(data, edge.label)
+	}
+	for _, edge := range node.edges {
+		data = appendUint(data, uint64(address-edge.target), targetWidth)
+	}
+	for _, edge := range node.edges {
+		data = appendUint(data, edge.output, outputWidth)
+	}
+	b.data = data
+	b.register[string(signature)] = address
+
+	return address
+}
+
+// nodeSignature возвращает сигнатуру состояния, не зависящую от его адреса.
+func (b *Builder) nodeSignature(node *builderNode) []byte {
+	key := b.signature[:0]
+	if node.final {
+		key = append(key, 1)
+	} else {
+		key = append(key, 0)
+	}
+	key = binary.AppendUvarint(key, node.finalOutput)
+	for _, edge := range node.edges {
+		key = append(key, edge.label)
+		key = binary.AppendUvarint(key, edge.output)
+		key = binary.AppendUvarint(key, uint64(edge.target))
+	}
+	b.signature = key
+
+	return key
+}
+
+// prepend добавляет выход output ко всем переходам и к выходу конечного состояния.
+func (node *builderNode) prepend(output uint64) {
+	for i := range node.edges {
+		node.edges[i].output += output
+	}
+	if node.final {
+		node.finalOutput += output
+	}
+}
+
+// width возвращает количество байт, необходимое для записи числа v.
+func width(v uint64) int {
+	n := 0
+	for ; v > 0; v >>= 8 {
+		n++
+	}
+
+	return n
+}
+
+func appendUint(data []byte, v uint64, width int) []byte {
+	for i := 0; i < width; i++ {
+		data = append(data, byte(v>>(8*i)))
+	}
+
+	return data
+}
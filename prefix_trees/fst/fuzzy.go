@@ -0,0 +1,104 @@
+package fst
+
+// FuzzySearch перебирает все ключи, которые отличаются от key не более чем на
+// maxDistance операций вставки, удаления или замены байта (расстояние Левенштейна),
+// и для каждого из них вызывает функцию f с найденным ключом, его выходом
+// и расстоянием до него.
+//
+// Преобразователь пересекается с автоматом Левенштейна: при обходе состояний для
+// каждой глубины вычисляется строка матрицы расстояний между префиксом пути и ключом
+// key. Если минимальное значение в строке превышает maxDistance, то переход
+// пропускается. Общие окончания ключей при этом могут посещаться несколько раз,
+// по одному разу для каждого пути к ним.
+func (m *Map) FuzzySearch(key []byte, maxDistance int, f func(key []byte, value uint64, distance int) error) error {
+	if m.data == nil {
+		return nil
+	}
+
+	s := fuzzySearch{m: m, key: key, maxDistance: maxDistance, f: f}
+
+	// первая строка матрицы - расстояния от пустого префикса до префиксов ключа
+	row := s.row(0)
+	for i := range row {
+		row[i] = i
+	}
+	root := m.state(m.root)
+	if root.final && row[len(key)] <= maxDistance {
+		if err := f(nil, root.finalOutput, row[len(key)]); err != nil {
+			return err
+		}
+	}
+
+	return s.search(root, 0, 0)
+}
+
+type fuzzySearch struct {
+	m           *Map
+	key         []byte
+	maxDistance int
+	f           func(key []byte, value uint64, distance int) error
+	// префикс текущего состояния
+	prefix []byte
+	// строки матрицы расстояний для каждой глубины обхода
+	rows [][]int
+}
+
+func (s *fuzzySearch) search(st state, depth int, output uint64) error {
+	prev := s.row(depth)
+	row := s.row(depth + 1)
+	n := len(s.key)
+
+	for i := 0; i < st.n; i++ {
+		k := st.labels[i]
+
+		row[0] = prev[0] + 1
+		best := row[0]
+		for j := 1; j <= n; j++ {
+			// замена (или совпадение) байта
+			d := prev[j-1]
+			if s.key[j-1] != k {
+				d++
+			}
+			// удаление байта из ключа
+			if prev[j]+1 < d {
+				d = prev[j] + 1
+			}
+			// вставка байта в ключ
+			if row[j-1]+1 < d {
+				d = row[j-1] + 1
+			}
+			row[j] = d
+			if d < best {
+				best = d
+			}
+		}
+
+		// все ключи за переходом находятся дальше допустимого расстояния
+		if best > s.maxDistance {
+			continue
+		}
+
+		s.prefix = append(s.prefix[:depth], k)
+		childOutput := output + st.output(i)
+		child := s.m.state(st.target(i))
+		if child.final && row[n] <= s.maxDistance {
+			if err := s.f(s.prefix, childOutput+child.finalOutput, row[n]); err != nil {
+				return err
+			}
+		}
+		if err := s.search(child, depth+1, childOutput); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// row возвращает строку матрицы расстояний для глубины depth.
+func (s *fuzzySearch) row(depth int) []int {
+	for len(s.rows) <= depth {
+		s.rows = append(s.rows, make([]int, len(s.key)+1))
+	}
+
+	return s.rows[depth]
+}
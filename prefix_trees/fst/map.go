@@ -0,0 +1,219 @@
+// Package fst содержит конечный преобразователь (finite state transducer),
+// отображающий байтовые ключи на числа uint64.
+//
+// Как и минимальный автомат пакета dawg, преобразователь объединяет общие префиксы
+// и общие суффиксы ключей, а выходы ключей распределяются по переходам так, чтобы
+// эквивалентные окончания ключей с разными выходами также совпадали. Состояния
+// записываются в один слайс байт, смещения целевых состояний и выходы хранятся
+// числами минимальной для состояния ширины.
+package fst
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+// Map неизменяемое отображение ключей на числа в виде минимального преобразователя.
+// Реализует интерфейс prefix_trees.Reader и безопасно для одновременного чтения
+// из нескольких горутин.
+type Map struct {
+	data  []byte
+	root  uint32
+	count int
+}
+
+// NewFromSorted строит отображение из ключей, упорядоченных по возрастанию байт,
+// и соответствующих им выходов. Если ключи не упорядочены или повторяются,
+// возвращается ошибка ErrUnsortedKeys.
+func NewFromSorted[K prefix_trees.Key](keys []K, outputs []uint64) (*Map, error) {
+	if len(keys) != len(outputs) {
+		return nil, fmt.Errorf("keys count %d does not match outputs count %d", len(keys), len(outputs))
+	}
+
+	b := NewBuilder()
+	for i, key := range keys {
+		if err := b.Add([]byte(key), outputs[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Finish(), nil
+}
+
+func (m *Map) Count() int {
+	return m.count
+}
+
+// Size возвращает размер данных преобразователя в байтах.
+func (m *Map) Size() int {
+	return len(m.data)
+}
+
+func (m *Map) Get(key []byte) uint64 {
+	v, _ := m.Find(key)
+
+	return v
+}
+
+func (m *Map) Find(key []byte) (uint64, bool) {
+	if m.data == nil {
+		return 0, false
+	}
+
+	s := m.state(m.root)
+	output := uint64(0)
+	for _, k := range key {
+		i, ok := s.find(k)
+		if !ok {
+			return 0, false
+		}
+		output += s.output(i)
+		s = m.state(s.target(i))
+	}
+	if !s.final {
+		return 0, false
+	}
+
+	return output + s.finalOutput, true
+}
+
+// Walk перебирает ключи в порядке возрастания и для каждого из них вызывает
+// функцию f. Слайс ключа используется повторно и должен быть скопирован,
+// если он нужен после возврата из f.
+func (m *Map) Walk(f func(key []byte, value uint64) error) error {
+	return m.WalkPrefix(nil, f)
+}
+
+// WalkPrefix перебирает в порядке возрастания ключи, начинающиеся с префикса
+// prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+// Ключи с общим префиксом образуют непрерывный диапазон ключей.
+func (m *Map) WalkPrefix(prefix []byte, f func(key []byte, value uint64) error) error {
+	if m.data == nil {
+		return nil
+	}
+
+	s := m.state(m.root)
+	output := uint64(0)
+	for _, k := range prefix {
+		i, ok := s.find(k)
+		if !ok {
+			return nil
+		}
+		output += s.output(i)
+		s = m.state(s.target(i))
+	}
+
+	key := make([]byte, len(prefix), len(prefix)+32)
+	copy(key, prefix)
+
+	return m.walk(s, key, output, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (m *Map) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(m.WalkPrefix, prefix, limit)
+}
+
+func (m *Map) MarshalJSON() ([]byte, error) {
+	var data bytes.Buffer
+	if err := m.EncodeJSON(&data); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// EncodeJSON записывает отображение в w объектом JSON в порядке возрастания ключей.
+func (m *Map) EncodeJSON(w io.Writer, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Encode(w, m.Walk, options...)
+}
+
+func (m *Map) walk(s state, key []byte, output uint64, f func(key []byte, value uint64) error) error {
+	if s.final {
+		if err := f(key, output+s.finalOutput); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < s.n; i++ {
+		err := m.walk(m.state(s.target(i)), append(key, s.labels[i]), output+s.output(i), f)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// state - прочитанный заголовок состояния. Слайсы ссылаются на данные преобразователя.
+type state struct {
+	address     uint32
+	final       bool
+	finalOutput uint64
+	n           int
+	labels      []byte
+	targetWidth int
+	targets     []byte
+	outputWidth int
+	outputs     []byte
+}
+
+// state читает состояние по адресу address (формат описан в Builder.compile).
+func (m *Map) state(address uint32) state {
+	data := m.data[address:]
+	s := state{address: address, final: data[0]&flagFinal != 0}
+	flags := data[0]
+
+	n, size := binary.Uvarint(data[1:])
+	data = data[1+size:]
+	s.n = int(n)
+	s.targetWidth, s.outputWidth = int(data[0]&0x0F), int(data[0]>>4)
+	data = data[1:]
+
+	if flags&flagFinalOutput != 0 {
+		s.finalOutput, size = binary.Uvarint(data)
+		data = data[size:]
+	}
+
+	s.labels = data[:s.n]
+	data = data[s.n:]
+	s.targets = data[:s.n*s.targetWidth]
+	s.outputs = data[s.n*s.targetWidth : s.n*(s.targetWidth+s.outputWidth)]
+
+	return s
+}
+
+// find возвращает номер перехода по байту k.
+func (s *state) find(k byte) (int, bool) {
+	// переходы упорядочены по байту, поэтому используется двоичный поиск
+	i := sort.Search(s.n, func(i int) bool {
+		return s.labels[i] >= k
+	})
+
+	return i, i < s.n && s.labels[i] == k
+}
+
+// target возвращает адрес целевого состояния перехода i.
+func (s *state) target(i int) uint32 {
+	return s.address - uint32(readUint(s.targets[i*s.targetWidth:], s.targetWidth))
+}
+
+func (s *state) output(i int) uint64 {
+	return readUint(s.outputs[i*s.outputWidth:], s.outputWidth)
+}
+
+func readUint(data []byte, width int) uint64 {
+	v := uint64(0)
+	for i := 0; i < width; i++ {
+		v |= uint64(data[i]) << (8 * i)
+	}
+
+	return v
+}
@@ -0,0 +1,38 @@
+package fst
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder_SharesSuffixes(t *testing.T) {
+	m, err := NewFromSorted([]string{"ab", "cb", "mon", "thurs", "tues"}, []uint64{5, 7, 2, 4, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// окончания "b" с разными выходами совпадают, так как выходы перенесены
+	// на первые переходы
+	assert.Equal(t, m.target(m.root, "a"), m.target(m.root, "c"))
+	// общее окончание "s" ключей "thurs" и "tues"
+	assert.Equal(t, m.target(m.root, "thur"), m.target(m.root, "tue"))
+	assert.Equal(t, uint64(5), m.Get([]byte("ab")))
+	assert.Equal(t, uint64(7), m.Get([]byte("cb")))
+	assert.Equal(t, uint64(4), m.Get([]byte("thurs")))
+	assert.Equal(t, uint64(3), m.Get([]byte("tues")))
+}
+
+// target возвращает адрес состояния, в которое преобразователь переходит по пути path.
+func (m *Map) target(address uint32, path string) uint32 {
+	for i := 0; i < len(path); i++ {
+		s := m.state(address)
+		j, ok := s.find(path[i])
+		if !ok {
+			panic("missing transition")
+		}
+		address = s.target(j)
+	}
+
+	return address
+}
@@ -0,0 +1,360 @@
+package fst_test
+
+import (
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/byte_suffix_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/fst"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestNewFromSorted(t *testing.T) {
+	keys := sortedCountries()
+	outputs := make([]uint64, len(keys))
+	for i := range outputs {
+		outputs[i] = uint64(rand.Int63())
+	}
+
+	m, err := fst.NewFromSorted(keys, outputs)
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(keys), m.Count())
+	for i, key := range keys {
+		v, found := m.Find([]byte(key))
+		assert.True(t, found, "at key: %s", key)
+		assert.Equal(t, outputs[i], v, "at key: %s", key)
+	}
+	for _, key := range []string{"", "A", "Bosnia", "Chadd", "Zz", "q"} {
+		_, found := m.Find([]byte(key))
+		assert.False(t, found, "at key: %s", key)
+	}
+
+	i := 0
+	err = m.Walk(func(key []byte, value uint64) error {
+		assert.Equal(t, keys[i], string(key))
+		assert.Equal(t, outputs[i], value, "at key: %s", key)
+		i++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, len(keys), i)
+}
+
+func TestNewFromSorted_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+	}{
+		{name: "unsorted", keys: []string{"b", "a"}},
+		{name: "duplicate", keys: []string{"a", "a"}},
+		{name: "prefix after key", keys: []string{"ab", "a"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := fst.NewFromSorted(test.keys, make([]uint64, len(test.keys)))
+
+			assert.ErrorIs(t, err, fst.ErrUnsortedKeys)
+		})
+	}
+
+	_, err := fst.NewFromSorted([]string{"a"}, nil)
+	assert.Error(t, err)
+}
+
+func TestMap_PrefixKeys(t *testing.T) {
+	// выходы ключей, являющихся префиксами других ключей, хранятся в конечных состояниях
+	keys := []string{"", "a", "ab", "abc", "abd", "b"}
+	outputs := []uint64{10, 3, 20, 1, 1, 0}
+
+	m, err := fst.NewFromSorted(keys, outputs)
+
+	assert.NoError(t, err)
+	for i, key := range keys {
+		v, found := m.Find([]byte(key))
+		assert.True(t, found, "at key: %s", key)
+		assert.Equal(t, outputs[i], v, "at key: %s", key)
+	}
+}
+
+func TestMap_RandomKeys(t *testing.T) {
+	items := randomItems(5000)
+	m := build(t, items)
+
+	assert.Equal(t, items.Count(), m.Count())
+	_ = items.Walk(func(key []byte, value uint64) error {
+		v, found := m.Find(key)
+		assert.True(t, found, "at key: %q", key)
+		assert.Equal(t, value, v, "at key: %q", key)
+		return nil
+	})
+	for _, prefix := range []string{"", "a", "ab", "ba", "cc", "d"} {
+		assert.Equal(t, items.KeysWithPrefix([]byte(prefix), 0), m.KeysWithPrefix([]byte(prefix), 0), "at prefix: %s", prefix)
+	}
+	assert.Len(t, m.KeysWithPrefix([]byte("a"), 3), 3)
+}
+
+func TestMap_FuzzySearch(t *testing.T) {
+	items := &byte_trie.Array[uint64]{}
+	for i, country := range sortedCountries() {
+		items.Put([]byte(country), uint64(i))
+	}
+	m := build(t, items)
+
+	for _, query := range []string{"Chda", "Bosnia", "Nigr", ""} {
+		for distance := 0; distance <= 2; distance++ {
+			want := map[string]uint64{}
+			_ = items.FuzzySearch([]byte(query), distance, func(key []byte, value uint64, d int) error {
+				want[string(key)] = value
+				return nil
+			})
+			got := map[string]uint64{}
+			err := m.FuzzySearch([]byte(query), distance, func(key []byte, value uint64, d int) error {
+				got[string(key)] = value
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, got, "query %q, distance %d", query, distance)
+		}
+	}
+}
+
+func TestMap_Match(t *testing.T) {
+	items := &byte_trie.Array[uint64]{}
+	for i, country := range sortedCountries() {
+		items.Put([]byte(country), uint64(i))
+	}
+	m := build(t, items)
+
+	for _, pattern := range []string{"*", "C*", "*land", "?h*", "[A-C]*a", "*[!a-z]", "Chad"} {
+		var want, got []string
+		_ = items.Match([]byte(pattern), func(key []byte, value uint64) error {
+			want = append(want, string(key))
+			return nil
+		})
+		err := m.Match([]byte(pattern), func(key []byte, value uint64) error {
+			got = append(got, string(key))
+			assert.Equal(t, items.Get(key), value, "at key: %s", key)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, "at pattern: %s", pattern)
+	}
+
+	err := m.Match([]byte("[a-"), func(key []byte, value uint64) error { return nil })
+	assert.ErrorIs(t, err, fst.ErrInvalidPattern)
+}
+
+func TestMap_Empty(t *testing.T) {
+	m, err := fst.NewFromSorted[string](nil, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, m.Count())
+	_, found := m.Find(nil)
+	assert.False(t, found)
+	assert.Empty(t, m.KeysWithPrefix(nil, 0))
+}
+
+func TestMap_MarshalJSON(t *testing.T) {
+	m, err := fst.NewFromSorted([]string{"alpha", "beta"}, []uint64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := m.MarshalJSON()
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"alpha":1,"beta":2}`, string(data))
+}
+
+func BenchmarkMap_Build(b *testing.B) {
+	cities := sortedCities(b)
+	outputs := make([]uint64, len(cities))
+	for n := range outputs {
+		outputs[n] = uint64(n + 1)
+	}
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	var m *fst.Map
+	for i := 0; i < b.N; i++ {
+		var err error
+		if m, err = fst.NewFromSorted(cities, outputs); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(m.Size()), "bytes")
+}
+
+func BenchmarkMap_Get(b *testing.B) {
+	cities := sortedCities(b)
+	outputs := make([]uint64, len(cities))
+	for n := range outputs {
+		outputs[n] = uint64(n + 1)
+	}
+	m, err := fst.NewFromSorted(cities, outputs)
+	if err != nil {
+		b.Fatal(err)
+	}
+	runtime.GC()
+
+	b.ResetTimer()
+
+	benchmarks := []struct {
+		name     string
+		cityName string
+	}{
+		{
+			name:     "short name",
+			cityName: "Adville",
+		},
+		{
+			name:     "long name",
+			cityName: "Advocate Lutheran General Childrens Hospital",
+		},
+		{
+			name:     "long unique suffix",
+			cityName: "Advocate Services Medical Transportation",
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, found := m.Find([]byte(bm.cityName))
+				if !found {
+					b.Fatal("element not found")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMap_Memory измеряет объем кучи, занятой отображением названий городов
+// на их номера, в сравнении с byte_trie.Array[int] и byte_suffix_trie.Array[int].
+func BenchmarkMap_Memory(b *testing.B) {
+	cities := sortedCities(b)
+
+	implementations := []struct {
+		name  string
+		build func() (any, error)
+	}{
+		{
+			name: "fst",
+			build: func() (any, error) {
+				outputs := make([]uint64, len(cities))
+				for n := range outputs {
+					outputs[n] = uint64(n + 1)
+				}
+				return fst.NewFromSorted(cities, outputs)
+			},
+		},
+		{
+			name: "byte trie",
+			build: func() (any, error) {
+				trie := &byte_trie.Array[int]{}
+				for n, city := range cities {
+					trie.Put([]byte(city), n+1)
+				}
+				return trie, nil
+			},
+		},
+		{
+			name: "byte suffix trie",
+			build: func() (any, error) {
+				trie := &byte_suffix_trie.Array[int]{}
+				for n, city := range cities {
+					trie.Put([]byte(city), n+1)
+				}
+				return trie, nil
+			},
+		},
+	}
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			var heap int64
+			for i := 0; i < b.N; i++ {
+				before := heapInUse()
+				m, err := impl.build()
+				if err != nil {
+					b.Fatal(err)
+				}
+				heap = heapInUse() - before
+				runtime.KeepAlive(m)
+			}
+			b.ReportMetric(float64(heap), "heap-bytes")
+			b.ReportMetric(float64(heap)/float64(len(cities)), "bytes/key")
+		})
+	}
+}
+
+func randomItems(count int) *byte_trie.Array[uint64] {
+	items := &byte_trie.Array[uint64]{}
+	for i := 0; i < count; i++ {
+		key := make([]byte, rand.Intn(8))
+		for j := range key {
+			key[j] = "abcd\x00\xff"[rand.Intn(6)]
+		}
+		// выходы разной величины, чтобы проверить ширину чисел в состояниях
+		items.Put(key, uint64(rand.Int63n(1<<(rand.Intn(8)*8+1))))
+	}
+
+	return items
+}
+
+// build строит преобразователь из значений items, перебирая их в порядке возрастания.
+func build(t *testing.T, items *byte_trie.Array[uint64]) *fst.Map {
+	t.Helper()
+
+	b := fst.NewBuilder()
+	err := items.Walk(func(key []byte, value uint64) error {
+		return b.Add(key, value)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b.Finish()
+}
+
+// sortedCountries возвращает упорядоченные названия стран без повторов.
+func sortedCountries() []string {
+	keys := append([]string(nil), fixtures.Countries...)
+	sort.Strings(keys)
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			unique = append(unique, key)
+		}
+	}
+
+	return unique
+}
+
+// sortedCities возвращает упорядоченные названия городов без повторов.
+func sortedCities(b *testing.B) []string {
+	cities := fixtures.CitiesT(b)
+	sort.Strings(cities)
+	unique := cities[:0]
+	for i, city := range cities {
+		if i == 0 || city != cities[i-1] {
+			unique = append(unique, city)
+		}
+	}
+
+	return unique
+}
+
+// heapInUse возвращает объем кучи после сборки мусора.
+func heapInUse() int64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	return int64(stats.HeapAlloc)
+}
@@ -0,0 +1,69 @@
+package fst
+
+import "github.com/strider2038/algos/prefix_trees/internal/glob"
+
+// ErrInvalidPattern - ошибка разбора шаблона поиска.
+var ErrInvalidPattern = glob.ErrInvalidPattern
+
+// Match перебирает в порядке возрастания все ключи, которые соответствуют шаблону
+// pattern, и для каждого из них вызывает функцию f.
+//
+// Поддерживаемые элементы шаблона:
+//
+//	?       любой байт
+//	*       любая последовательность байт (в том числе пустая)
+//	[abc]   любой байт из перечисленных
+//	[a-z]   любой байт из диапазона
+//	[!abc]  любой байт, кроме перечисленных (также [^abc])
+//	\c      байт c без специального значения
+//
+// Шаблон преобразуется в недетерминированный автомат, который пересекается
+// с преобразователем: состояния автомата отслеживаются одновременно при обходе
+// переходов, и переходы, байты которых не допускает ни одно активное состояние,
+// не посещаются.
+func (m *Map) Match(pattern []byte, f func(key []byte, value uint64) error) error {
+	automaton, err := glob.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	if m.data == nil {
+		return nil
+	}
+
+	mt := matcher{m: m, automaton: automaton, f: f}
+
+	return mt.match(m.state(m.root), 0, 0)
+}
+
+type matcher struct {
+	m         *Map
+	automaton *glob.Automaton
+	f         func(key []byte, value uint64) error
+	// ключ текущего состояния
+	key []byte
+}
+
+func (mt *matcher) match(st state, depth int, output uint64) error {
+	if st.final && mt.automaton.Accepts(depth) {
+		if err := mt.f(mt.key, output+st.finalOutput); err != nil {
+			return err
+		}
+	}
+
+	// маска допустимых байт всех активных состояний
+	allowed := mt.automaton.Allowed(depth)
+
+	for i := 0; i < st.n; i++ {
+		k := st.labels[i]
+		if !allowed.Has(k) || !mt.automaton.Step(depth, k) {
+			continue
+		}
+
+		mt.key = append(mt.key[:depth], k)
+		if err := mt.match(mt.m.state(st.target(i)), depth+1, output+st.output(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+// Package binfmt содержит общие элементы бинарного формата префиксных деревьев:
+// заголовок с версией, примитивы записи и чтения и контрольную сумму.
+//
+// Формат данных:
+//
+//	magic   4 байта "PTRI"
+//	version 1 байт, версия формата
+//	kind    1 байт, тип дерева
+//	payload структура дерева, записывается реализацией
+//	crc32   4 байта, контрольная сумма IEEE всех предыдущих байт (little endian)
+package binfmt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// Version - текущая версия формата.
+const Version = 1
+
+// Типы деревьев.
+const (
+	KindAlphabetTrie   = 1
+	KindByteTrie       = 2
+	KindByteShardTrie  = 3
+	KindByteSuffixTrie = 4
+)
+
+const (
+	magic      = "PTRI"
+	headerSize = len(magic) + 2
+	sumSize    = 4
+)
+
+// Encoder записывает данные дерева в буфер.
+type Encoder struct {
+	data []byte
+}
+
+// NewEncoder создает буфер с заголовком для дерева типа kind.
+func NewEncoder(kind byte) *Encoder {
+	e := &Encoder{data: make([]byte, 0, 256)}
+	e.data = append(e.data, magic...)
+	e.data = append(e.data, Version, kind)
+
+	return e
+}
+
+func (e *Encoder) Byte(b byte) {
+	e.data = append(e.data, b)
+}
+
+func (e *Encoder) Uvarint(v uint64) {
+	e.data = binary.AppendUvarint(e.data, v)
+}
+
+func (e *Encoder) Uint64(v uint64) {
+	e.data = binary.LittleEndian.AppendUint64(e.data, v)
+}
+
+// Bytes записывает слайс байт с префиксом длины.
+func (e *Encoder) Bytes(b []byte) {
+	e.Uvarint(uint64(len(b)))
+	e.data = append(e.data, b...)
+}
+
+// Raw записывает слайс байт без префикса длины.
+func (e *Encoder) Raw(b []byte) {
+	e.data = append(e.data, b...)
+}
+
+// WriteValue записывает значение с префиксом длины с помощью кодека codec.
+func WriteValue[V any](e *Encoder, codec prefix_trees.ValueCodec[V], value V) error {
+	// длина значения заранее неизвестна, поэтому значение записывается в конец
+	// буфера, а затем сдвигается на размер префикса длины
+	start := len(e.data)
+	data, err := codec.AppendValue(e.data, value)
+	if err != nil {
+		return err
+	}
+	size := len(data) - start
+
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(size))
+	data = append(data, prefix[:n]...)
+	copy(data[start+n:], data[start:start+size])
+	copy(data[start:], prefix[:n])
+	e.data = data
+
+	return nil
+}
+
+// Finish добавляет контрольную сумму и возвращает данные.
+func (e *Encoder) Finish() []byte {
+	return binary.LittleEndian.AppendUint32(e.data, crc32.ChecksumIEEE(e.data))
+}
+
+// Decoder читает данные дерева. Первая ошибка чтения сохраняется, после нее
+// все методы возвращают нулевые значения.
+type Decoder struct {
+	data []byte
+	err  error
+}
+
+// NewDecoder проверяет заголовок и контрольную сумму данных дерева типа kind.
+func NewDecoder(data []byte, kind byte) (*Decoder, error) {
+	if len(data) < headerSize+sumSize || string(data[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: invalid header", prefix_trees.ErrUnsupportedFormat)
+	}
+	if data[len(magic)] != Version {
+		return nil, fmt.Errorf("%w: version %d", prefix_trees.ErrUnsupportedFormat, data[len(magic)])
+	}
+
+	body := data[:len(data)-sumSize]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[len(body):]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", prefix_trees.ErrCorruptedData)
+	}
+	if data[len(magic)+1] != kind {
+		return nil, fmt.Errorf("%w: trie kind %d", prefix_trees.ErrUnsupportedFormat, data[len(magic)+1])
+	}
+
+	return &Decoder{data: body[headerSize:]}, nil
+}
+
+func (d *Decoder) Byte() byte {
+	b := d.Raw(1)
+	if b == nil {
+		return 0
+	}
+
+	return b[0]
+}
+
+func (d *Decoder) Uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.Fail("invalid varint")
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return v
+}
+
+func (d *Decoder) Uint64() uint64 {
+	b := d.Raw(8)
+	if b == nil {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint64(b)
+}
+
+// Bytes читает слайс байт с префиксом длины. Возвращаемый слайс ссылается
+// на исходные данные.
+func (d *Decoder) Bytes() []byte {
+	size := d.Uvarint()
+	if d.err == nil && size > uint64(len(d.data)) {
+		d.Fail("unexpected end of data")
+	}
+
+	return d.Raw(int(size))
+}
+
+// Raw читает n байт. Возвращаемый слайс ссылается на исходные данные.
+func (d *Decoder) Raw(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data) {
+		d.Fail("unexpected end of data")
+		return nil
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+
+	return b
+}
+
+// ReadValue читает значение, записанное функцией WriteValue.
+func ReadValue[V any](d *Decoder, codec prefix_trees.ValueCodec[V]) V {
+	var zero V
+	data := d.Bytes()
+	if d.err != nil {
+		return zero
+	}
+
+	value, err := codec.DecodeValue(data)
+	if err != nil {
+		d.err = fmt.Errorf("decode value: %w", err)
+		return zero
+	}
+
+	return value
+}
+
+// Fail сохраняет ошибку нарушения структуры данных.
+func (d *Decoder) Fail(reason string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: %s", prefix_trees.ErrCorruptedData, reason)
+	}
+}
+
+// Err возвращает первую ошибку чтения.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Finish возвращает первую ошибку чтения или ошибку, если данные прочитаны не полностью.
+func (d *Decoder) Finish() error {
+	if d.err == nil && len(d.data) > 0 {
+		d.Fail("unexpected trailing data")
+	}
+
+	return d.err
+}
@@ -0,0 +1,302 @@
+// Package cursor содержит общую реализацию курсора по узлам префиксного дерева
+// и навигации по упорядоченным ключам на его основе. Пакеты деревьев реализуют
+// только переходы между своими узлами (интерфейс Node).
+package cursor
+
+import "bytes"
+
+// Node - узел дерева, по которому перемещается курсор. N - тип самого узла
+// (как правило, указатель на узел).
+type Node[N any, V any] interface {
+	// HasValue возвращает true, если узел содержит значение.
+	HasValue() bool
+	// Value возвращает значение узла.
+	Value() V
+	// ChildCount возвращает количество дочерних узлов.
+	ChildCount() int
+	// Child возвращает дочерний узел с порядковым номером i в порядке ключей.
+	Child(i int) N
+	// AppendLabel добавляет к key часть ключа, соответствующую переходу
+	// от родителя к узлу.
+	AppendLabel(key []byte) []byte
+	// SeekChild находит положение искомого ключа key среди дочерних узлов.
+	// Если exact равен true, то ключ продолжается в дочернем узле i, а его первые
+	// size байт соответствуют переходу к этому узлу. Иначе i - номер первого
+	// дочернего узла с ключами больше искомого (или количество дочерних узлов,
+	// если таких нет), а отрицательное значение i означает, что ключ самого узла
+	// больше или равен искомому.
+	SeekChild(key []byte) (i, size int, exact bool)
+}
+
+// Cursor - курсор для перебора значений дерева в порядке ключей с произвольной
+// начальной позиции. Курсор хранит цепочку узлов от корня до текущего узла.
+type Cursor[N Node[N, V], V any] struct {
+	root N
+	// цепочка узлов от корня до текущего узла
+	nodes []N
+	// номера узлов цепочки среди дочерних узлов родителей:
+	// nodes[i+1] == nodes[i].Child(indices[i])
+	indices []int
+	// длины ключа до спуска к каждому из узлов цепочки
+	offsets []int
+	// ключ текущего узла
+	key   []byte
+	valid bool
+}
+
+// New создает курсор по дереву с корнем root. Перед использованием курсор
+// необходимо позиционировать.
+func New[N Node[N, V], V any](root N) *Cursor[N, V] {
+	return &Cursor[N, V]{root: root}
+}
+
+// Valid возвращает true, если курсор указывает на существующее значение.
+func (c *Cursor[N, V]) Valid() bool {
+	return c.valid
+}
+
+// Key возвращает ключ текущего значения. Слайс действителен до следующего
+// перемещения курсора.
+func (c *Cursor[N, V]) Key() []byte {
+	if !c.valid {
+		return nil
+	}
+
+	return c.key
+}
+
+// Value возвращает текущее значение.
+func (c *Cursor[N, V]) Value() V {
+	if !c.valid {
+		var zero V
+		return zero
+	}
+
+	return c.node().Value()
+}
+
+// First перемещает курсор на наименьший ключ.
+func (c *Cursor[N, V]) First() bool {
+	c.reset()
+
+	return c.settle()
+}
+
+// Last перемещает курсор на наибольший ключ.
+func (c *Cursor[N, V]) Last() bool {
+	c.reset()
+	c.descendLast()
+	if c.node().HasValue() {
+		c.valid = true
+		return true
+	}
+
+	return c.Prev()
+}
+
+// Seek перемещает курсор на наименьший ключ, больший или равный key.
+func (c *Cursor[N, V]) Seek(key []byte) bool {
+	c.reset()
+
+	for len(key) > 0 {
+		node := c.node()
+		i, size, exact := node.SeekChild(key)
+		switch {
+		case exact:
+			c.push(i)
+			key = key[size:]
+		case i < 0:
+			return c.settle()
+		case i < node.ChildCount():
+			c.push(i)
+			return c.settle()
+		default:
+			// все ключи поддерева меньше искомого, переходим к следующему поддереву
+			if !c.skip() {
+				return false
+			}
+			return c.settle()
+		}
+	}
+
+	return c.settle()
+}
+
+// Next перемещает курсор на следующий ключ.
+func (c *Cursor[N, V]) Next() bool {
+	for c.advance() {
+		if c.node().HasValue() {
+			c.valid = true
+			return true
+		}
+	}
+
+	c.valid = false
+
+	return false
+}
+
+// Prev перемещает курсор на предыдущий ключ.
+func (c *Cursor[N, V]) Prev() bool {
+	for c.retreat() {
+		if c.node().HasValue() {
+			c.valid = true
+			return true
+		}
+	}
+
+	c.valid = false
+
+	return false
+}
+
+func (c *Cursor[N, V]) node() N {
+	return c.nodes[len(c.nodes)-1]
+}
+
+func (c *Cursor[N, V]) reset() {
+	c.nodes = append(c.nodes[:0], c.root)
+	c.indices = c.indices[:0]
+	c.offsets = c.offsets[:0]
+	c.key = c.key[:0]
+	c.valid = false
+}
+
+// push - спуск к дочернему узлу с номером i.
+func (c *Cursor[N, V]) push(i int) {
+	child := c.node().Child(i)
+	c.nodes = append(c.nodes, child)
+	c.indices = append(c.indices, i)
+	c.offsets = append(c.offsets, len(c.key))
+	c.key = child.AppendLabel(c.key)
+}
+
+// pop - подъем к родительскому узлу. Возвращает номер покинутого узла.
+func (c *Cursor[N, V]) pop() int {
+	n := len(c.indices) - 1
+	i := c.indices[n]
+	c.nodes = c.nodes[:n+1]
+	c.indices = c.indices[:n]
+	c.key = c.key[:c.offsets[n]]
+	c.offsets = c.offsets[:n]
+
+	return i
+}
+
+// settle - если текущий узел не содержит значения, то перемещает курсор
+// на следующее значение.
+func (c *Cursor[N, V]) settle() bool {
+	if c.node().HasValue() {
+		c.valid = true
+		return true
+	}
+
+	return c.Next()
+}
+
+// advance - переход к следующему узлу при прямом обходе дерева
+// (узел, затем его дочерние узлы в порядке ключей).
+func (c *Cursor[N, V]) advance() bool {
+	if c.node().ChildCount() > 0 {
+		c.push(0)
+		return true
+	}
+
+	return c.skip()
+}
+
+// skip - переход к следующему узлу при прямом обходе с пропуском поддерева
+// текущего узла.
+func (c *Cursor[N, V]) skip() bool {
+	for len(c.indices) > 0 {
+		i := c.pop()
+		if i+1 < c.node().ChildCount() {
+			c.push(i + 1)
+			return true
+		}
+	}
+
+	return false
+}
+
+// retreat - переход к предыдущему узлу при прямом обходе дерева.
+func (c *Cursor[N, V]) retreat() bool {
+	if len(c.indices) == 0 {
+		return false
+	}
+
+	i := c.pop()
+	if i > 0 {
+		c.push(i - 1)
+		c.descendLast()
+	}
+
+	return true
+}
+
+// descendLast - спуск к последнему узлу поддерева при прямом обходе.
+func (c *Cursor[N, V]) descendLast() {
+	for n := c.node().ChildCount(); n > 0; n = c.node().ChildCount() {
+		c.push(n - 1)
+	}
+}
+
+// Min возвращает наименьший ключ дерева и его значение.
+func (c *Cursor[N, V]) Min() ([]byte, V, bool) {
+	return c.result(c.First())
+}
+
+// Max возвращает наибольший ключ дерева и его значение.
+func (c *Cursor[N, V]) Max() ([]byte, V, bool) {
+	return c.result(c.Last())
+}
+
+// Floor возвращает наибольший ключ, меньший или равный key, и его значение.
+func (c *Cursor[N, V]) Floor(key []byte) ([]byte, V, bool) {
+	if c.Seek(key) && bytes.Equal(c.key, key) {
+		return c.result(true)
+	}
+
+	return c.result(c.prevOrLast())
+}
+
+// Ceiling возвращает наименьший ключ, больший или равный key, и его значение.
+func (c *Cursor[N, V]) Ceiling(key []byte) ([]byte, V, bool) {
+	return c.result(c.Seek(key))
+}
+
+// Predecessor возвращает наибольший ключ, строго меньший key, и его значение.
+func (c *Cursor[N, V]) Predecessor(key []byte) ([]byte, V, bool) {
+	c.Seek(key)
+
+	return c.result(c.prevOrLast())
+}
+
+// Successor возвращает наименьший ключ, строго больший key, и его значение.
+func (c *Cursor[N, V]) Successor(key []byte) ([]byte, V, bool) {
+	if c.Seek(key) && bytes.Equal(c.key, key) {
+		return c.result(c.Next())
+	}
+
+	return c.result(c.valid)
+}
+
+// prevOrLast - после неудачного поиска с помощью Seek перемещает курсор
+// на предыдущий ключ или на наибольший ключ, если все ключи меньше искомого.
+func (c *Cursor[N, V]) prevOrLast() bool {
+	if c.valid {
+		return c.Prev()
+	}
+
+	return c.Last()
+}
+
+// result возвращает копию ключа и значение текущей позиции курсора.
+func (c *Cursor[N, V]) result(ok bool) ([]byte, V, bool) {
+	if !ok {
+		var zero V
+		return nil, zero, false
+	}
+
+	return append([]byte{}, c.key...), c.node().Value(), true
+}
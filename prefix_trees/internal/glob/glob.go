@@ -0,0 +1,220 @@
+// Package glob содержит общую реализацию поиска байтовых ключей по шаблону:
+// разбор шаблона и недетерминированный автомат, состояния которого отслеживаются
+// одновременно при обходе дерева в глубину.
+package glob
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPattern - ошибка разбора шаблона поиска.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+// Mask - битовая маска допустимых байт.
+type Mask [4]uint64
+
+// Has возвращает true, если байт c допустим маской.
+func (m *Mask) Has(c byte) bool {
+	return m[c>>6]&(1<<(c&0x3F)) != 0
+}
+
+func (m *Mask) set(c byte) {
+	m[c>>6] |= 1 << (c & 0x3F)
+}
+
+func (m *Mask) fill() {
+	for i := range m {
+		m[i] = 0xFFFFFFFFFFFFFFFF
+	}
+}
+
+// token - элемент шаблона: маска допустимых байт и признак повторения.
+type token struct {
+	mask Mask
+	// элемент '*' - допускает любое количество байт из маски
+	star bool
+}
+
+// Automaton - недетерминированный автомат шаблона. Для каждой глубины обхода
+// хранится множество активных состояний (состояние i - распознаны первые
+// i элементов шаблона), множество глубины depth+1 вычисляется методом Step.
+//
+// Поддерживаемые элементы шаблона:
+//
+//	?       любой байт
+//	*       любая последовательность байт (в том числе пустая)
+//	[abc]   любой байт из перечисленных
+//	[a-z]   любой байт из диапазона
+//	[!abc]  любой байт, кроме перечисленных (также [^abc])
+//	\c      байт c без специального значения
+type Automaton struct {
+	tokens []token
+	stack  [][]bool
+}
+
+// Compile разбирает шаблон и возвращает автомат в начальном состоянии (глубина 0).
+// Ошибки разбора оборачивают ErrInvalidPattern.
+func Compile(pattern []byte) (*Automaton, error) {
+	tokens, err := compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Automaton{tokens: tokens}
+	states := a.states(0)
+	states[0] = true
+	a.closure(states)
+
+	return a, nil
+}
+
+// Accepts возвращает true, если ключ глубины depth соответствует шаблону целиком.
+func (a *Automaton) Accepts(depth int) bool {
+	return a.states(depth)[len(a.tokens)]
+}
+
+// Allowed возвращает объединение масок допустимых байт всех активных состояний
+// глубины depth. Переходы по остальным байтам не ведут к совпадениям.
+func (a *Automaton) Allowed(depth int) Mask {
+	states := a.states(depth)
+	var allowed Mask
+	for i, token := range a.tokens {
+		if states[i] {
+			for j := range allowed {
+				allowed[j] |= token.mask[j]
+			}
+		}
+	}
+
+	return allowed
+}
+
+// Step вычисляет множество состояний на глубине depth+1 после перехода по байту k.
+// Возвращает false, если ни одно состояние недостижимо.
+func (a *Automaton) Step(depth int, k byte) bool {
+	states := a.states(depth)
+	next := a.states(depth + 1)
+	for i := range next {
+		next[i] = false
+	}
+
+	reachable := false
+	for i, token := range a.tokens {
+		if !states[i] || !token.mask.Has(k) {
+			continue
+		}
+		if token.star {
+			next[i] = true
+		} else {
+			next[i+1] = true
+		}
+		reachable = true
+	}
+	a.closure(next)
+
+	return reachable
+}
+
+// closure добавляет в множество состояния, достижимые пропуском элементов '*'.
+func (a *Automaton) closure(states []bool) {
+	for i, token := range a.tokens {
+		if states[i] && token.star {
+			states[i+1] = true
+		}
+	}
+}
+
+// states возвращает множество состояний для глубины depth.
+func (a *Automaton) states(depth int) []bool {
+	for len(a.stack) <= depth {
+		a.stack = append(a.stack, make([]bool, len(a.tokens)+1))
+	}
+
+	return a.stack[depth]
+}
+
+// compile разбирает шаблон в последовательность элементов.
+func compile(pattern []byte) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(pattern); i++ {
+		var t token
+
+		switch c := pattern[i]; c {
+		case '?':
+			t.mask.fill()
+		case '*':
+			// последовательные элементы '*' эквивалентны одному
+			if len(tokens) > 0 && tokens[len(tokens)-1].star {
+				continue
+			}
+			t.mask.fill()
+			t.star = true
+		case '[':
+			end, err := compileClass(pattern, i+1, &t.mask)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case '\\':
+			i++
+			if i >= len(pattern) {
+				return nil, fmt.Errorf("%w: trailing escape character", ErrInvalidPattern)
+			}
+			t.mask.set(pattern[i])
+		default:
+			t.mask.set(c)
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens, nil
+}
+
+// compileClass разбирает класс символов, начинающийся с позиции start (после '['),
+// и заполняет маску. Возвращает позицию закрывающей скобки.
+func compileClass(pattern []byte, start int, mask *Mask) (int, error) {
+	i := start
+	negate := i < len(pattern) && (pattern[i] == '!' || pattern[i] == '^')
+	if negate {
+		i++
+	}
+
+	for first := true; ; first = false {
+		if i >= len(pattern) {
+			return 0, fmt.Errorf("%w: unclosed character class at %d", ErrInvalidPattern, start-1)
+		}
+		// закрывающая скобка в начале класса считается обычным символом
+		if pattern[i] == ']' && !first {
+			break
+		}
+
+		lo := pattern[i]
+		if lo == '\\' && i+1 < len(pattern) {
+			i++
+			lo = pattern[i]
+		}
+		hi := lo
+		if i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']' {
+			hi = pattern[i+2]
+			i += 2
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("%w: invalid range %c-%c", ErrInvalidPattern, lo, hi)
+		}
+		for c := int(lo); c <= int(hi); c++ {
+			mask.set(byte(c))
+		}
+		i++
+	}
+
+	if negate {
+		for j := range mask {
+			mask[j] = ^mask[j]
+		}
+	}
+
+	return i, nil
+}
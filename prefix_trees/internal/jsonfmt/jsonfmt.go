@@ -0,0 +1,194 @@
+// Package jsonfmt содержит общую реализацию потокового кодирования префиксных
+// деревьев в JSON и декодирования из JSON.
+package jsonfmt
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+var errInvalidEscape = errors.New("invalid key escape sequence")
+
+// Encode записывает в w объект JSON с элементами, которые перебирает функция walk.
+// Каждый элемент кодируется отдельно и сразу записывается в буферизованный поток,
+// поэтому документ целиком в памяти не строится.
+func Encode[K prefix_trees.Key, V any](
+	w io.Writer,
+	walk func(f func(key K, value V) error) error,
+	options ...prefix_trees.JSONOption,
+) error {
+	opts := newOptions(options)
+	out := bufio.NewWriter(w)
+	out.WriteByte('{')
+	i := 0
+	var key []byte
+
+	err := walk(func(k K, value V) error {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		i++
+
+		key = appendKey(key[:0], []byte(k), opts.Keys)
+		out.Write(key)
+		out.WriteByte(':')
+
+		v, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(v)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	out.WriteByte('}')
+
+	return out.Flush()
+}
+
+// Decode читает из r объект JSON и для каждого его элемента вызывает функцию put.
+// Документ null вместо объекта не изменяет дерево, а элемент со значением null
+// добавляется с нулевым значением V, как при декодировании в map. Чтение
+// останавливается после закрывающей скобки объекта.
+func Decode[K prefix_trees.Key, V any](
+	r io.Reader,
+	put func(key K, value V) error,
+	options ...prefix_trees.JSONOption,
+) error {
+	opts := newOptions(options)
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("decode trie: unexpected token %v, object expected", token)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, err := decodeKey(token.(string), opts.Keys)
+		if err != nil {
+			return fmt.Errorf("decode trie key %q: %w", token, err)
+		}
+
+		var value V
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("decode trie value at key %q: %w", token, err)
+		}
+		if err := put(K(key), value); err != nil {
+			return err
+		}
+	}
+
+	_, err = decoder.Token()
+
+	return err
+}
+
+func newOptions(options []prefix_trees.JSONOption) prefix_trees.JSONOptions {
+	var opts prefix_trees.JSONOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	return opts
+}
+
+// appendKey добавляет к data ключ в виде строки JSON.
+func appendKey(data, key []byte, encoding prefix_trees.KeyEncoding) []byte {
+	var s string
+	switch encoding {
+	case prefix_trees.KeyBase64:
+		// алфавит base64 не требует экранирования в JSON
+		data = append(data, '"')
+		data = append(data, base64.StdEncoding.EncodeToString(key)...)
+		return append(data, '"')
+	case prefix_trees.KeyEscaped:
+		s = escapeKey(key)
+	default:
+		s = string(key)
+	}
+
+	k, _ := json.Marshal(s)
+
+	return append(data, k...)
+}
+
+// escapeKey заменяет некорректные для UTF-8 байты последовательностями \xHH
+// и удваивает обратную косую черту.
+func escapeKey(key []byte) string {
+	escaped := make([]byte, 0, len(key))
+	for len(key) > 0 {
+		r, size := utf8.DecodeRune(key)
+		switch {
+		case r == utf8.RuneError && size == 1:
+			escaped = append(escaped, '\\', 'x', hexDigits[key[0]>>4], hexDigits[key[0]&0xF])
+		case r == '\\':
+			escaped = append(escaped, '\\', '\\')
+		default:
+			escaped = append(escaped, key[:size]...)
+		}
+		key = key[size:]
+	}
+
+	return string(escaped)
+}
+
+func decodeKey(key string, encoding prefix_trees.KeyEncoding) (string, error) {
+	switch encoding {
+	case prefix_trees.KeyBase64:
+		k, err := base64.StdEncoding.DecodeString(key)
+		return string(k), err
+	case prefix_trees.KeyEscaped:
+		return unescapeKey(key)
+	default:
+		return key, nil
+	}
+}
+
+func unescapeKey(key string) (string, error) {
+	unescaped := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		if key[i] != '\\' {
+			unescaped = append(unescaped, key[i])
+			continue
+		}
+		if i+1 < len(key) && key[i+1] == '\\' {
+			unescaped = append(unescaped, '\\')
+			i++
+			continue
+		}
+		if i+4 > len(key) || key[i+1] != 'x' {
+			return "", errInvalidEscape
+		}
+		b, err := strconv.ParseUint(key[i+2:i+4], 16, 8)
+		if err != nil {
+			return "", errInvalidEscape
+		}
+		unescaped = append(unescaped, byte(b))
+		i += 3
+	}
+
+	return string(unescaped), nil
+}
+
+const hexDigits = "0123456789abcdef"
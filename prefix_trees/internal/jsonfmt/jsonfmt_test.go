@@ -0,0 +1,32 @@
+package jsonfmt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+func TestDecode_Null(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]int
+	}{
+		{name: "null document", data: `null`, want: map[string]int{}},
+		{name: "null member", data: `{"a":null,"b":2}`, want: map[string]int{"a": 0, "b": 2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := map[string]int{}
+			err := jsonfmt.Decode(strings.NewReader(test.data), func(key string, value int) error {
+				got[key] = value
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
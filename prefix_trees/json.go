@@ -0,0 +1,32 @@
+package prefix_trees
+
+// KeyEncoding - способ записи ключей в JSON.
+type KeyEncoding int
+
+const (
+	// KeyText записывает ключи строками JSON как есть. Байты ключей, не являющиеся
+	// корректным UTF-8, заменяются символом U+FFFD, поэтому двоичные ключи теряются.
+	KeyText KeyEncoding = iota
+	// KeyEscaped записывает ключи строками, в которых некорректные для UTF-8 байты
+	// заменены последовательностями \xHH, а обратная косая черта удвоена.
+	// Текстовые ключи остаются читаемыми, а двоичные восстанавливаются без потерь.
+	KeyEscaped
+	// KeyBase64 записывает ключи в кодировке base64 (RFC 4648, со знаками дополнения).
+	KeyBase64
+)
+
+// JSONOptions - параметры потокового кодирования дерева в JSON.
+type JSONOptions struct {
+	// Keys - способ записи ключей, по умолчанию KeyText.
+	Keys KeyEncoding
+}
+
+// JSONOption изменяет параметры потокового кодирования дерева в JSON.
+type JSONOption func(options *JSONOptions)
+
+// WithKeyEncoding устанавливает способ записи ключей.
+func WithKeyEncoding(encoding KeyEncoding) JSONOption {
+	return func(options *JSONOptions) {
+		options.Keys = encoding
+	}
+}
@@ -0,0 +1,39 @@
+package prefix_trees
+
+import "errors"
+
+// errStopWalk - служебная ошибка для досрочного завершения перебора дерева.
+var errStopWalk = errors.New("stop walk")
+
+// KeysWithPrefix возвращает ключи, начинающиеся с префикса prefix, в порядке их
+// перебора функцией walkPrefix (метод WalkPrefix дерева). Если limit больше нуля,
+// то возвращается не более limit ключей. Слайсы байт копируются, так как деревья
+// используют буфер ключа повторно. При ошибке перебора возвращаются ключи,
+// собранные до нее.
+func KeysWithPrefix[K Key, V any](
+	walkPrefix func(prefix K, f func(key K, value V) error) error,
+	prefix K,
+	limit int,
+) []K {
+	var keys []K
+
+	_ = walkPrefix(prefix, func(key K, value V) error {
+		keys = append(keys, cloneKey(key))
+		if limit > 0 && len(keys) >= limit {
+			return errStopWalk
+		}
+
+		return nil
+	})
+
+	return keys
+}
+
+// cloneKey копирует ключ. Строки неизменяемы и не копируются.
+func cloneKey[K Key](key K) K {
+	if _, ok := any(key).(string); ok {
+		return key
+	}
+
+	return K(append([]byte(nil), key...))
+}
@@ -0,0 +1,44 @@
+package prefix_trees_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+func TestKeysWithPrefix(t *testing.T) {
+	// перебор использует один буфер ключа, как и деревья пакета
+	buffer := make([]byte, 0, 8)
+	walkPrefix := func(prefix []byte, f func(key []byte, value int) error) error {
+		for i, key := range []string{"a", "ab", "abc"} {
+			buffer = append(buffer[:0], key...)
+			if err := f(buffer, i); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	keys := prefix_trees.KeysWithPrefix(walkPrefix, nil, 0)
+	limited := prefix_trees.KeysWithPrefix(walkPrefix, nil, 2)
+
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("ab"), []byte("abc")}, keys)
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("ab")}, limited)
+}
+
+func TestKeysWithPrefix_Error(t *testing.T) {
+	walkPrefix := func(prefix string, f func(key string, value int) error) error {
+		if err := f("a", 1); err != nil {
+			return err
+		}
+
+		return errors.New("walk failed")
+	}
+
+	keys := prefix_trees.KeysWithPrefix(walkPrefix, "", 0)
+
+	assert.Equal(t, []string{"a"}, keys)
+}
@@ -0,0 +1,97 @@
+package louds_trie
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// blockWords - количество слов в блоке индекса ранга (512 бит).
+const blockWords = 8
+
+// bitVector - неизменяемый битовый вектор с операциями rank и select.
+//
+// Для ускорения rank хранится количество единиц перед каждым блоком из 512 бит,
+// что добавляет к вектору около 6% памяти. Операция select выполняет двоичный
+// поиск по блокам и затем подсчет битов внутри блока.
+type bitVector struct {
+	words []uint64
+	// количество единиц перед началом каждого блока
+	ranks []uint32
+	size  int
+}
+
+// append добавляет бит в конец вектора.
+func (v *bitVector) append(bit bool) {
+	if v.size%64 == 0 {
+		v.words = append(v.words, 0)
+	}
+	if bit {
+		v.words[v.size/64] |= 1 << (v.size % 64)
+	}
+	v.size++
+}
+
+// build строит индекс ранга. Вызывается после добавления всех битов.
+func (v *bitVector) build() {
+	// дополнительный блок в конце нужен для rank1 от позиции за последним битом
+	v.ranks = make([]uint32, len(v.words)/blockWords+1)
+	rank := 0
+	for i, word := range v.words {
+		rank += bits.OnesCount64(word)
+		if (i+1)%blockWords == 0 {
+			v.ranks[(i+1)/blockWords] = uint32(rank)
+		}
+	}
+}
+
+func (v *bitVector) get(i int) bool {
+	return v.words[i/64]&(1<<(i%64)) != 0
+}
+
+// rank1 возвращает количество единиц в позициях [0, i).
+func (v *bitVector) rank1(i int) int {
+	w := i / 64
+	rank := int(v.ranks[w/blockWords])
+	for j := w / blockWords * blockWords; j < w; j++ {
+		rank += bits.OnesCount64(v.words[j])
+	}
+	if i%64 != 0 {
+		rank += bits.OnesCount64(v.words[w] << (64 - i%64))
+	}
+
+	return rank
+}
+
+// select0 возвращает позицию k-го нуля (нумерация с единицы). Нуль должен существовать.
+func (v *bitVector) select0(k int) int {
+	// последний блок, перед которым меньше k нулей
+	block := sort.Search(len(v.ranks), func(b int) bool {
+		return b*blockWords*64-int(v.ranks[b]) >= k
+	}) - 1
+	k -= block*blockWords*64 - int(v.ranks[block])
+
+	for w := block * blockWords; ; w++ {
+		zeros := ^v.words[w]
+		count := bits.OnesCount64(zeros)
+		if k <= count {
+			// снимаем младшие k-1 нулевых бита слова
+			for ; k > 1; k-- {
+				zeros &= zeros - 1
+			}
+			return w*64 + bits.TrailingZeros64(zeros)
+		}
+		k -= count
+	}
+}
+
+// nextZero возвращает позицию первого нуля, начиная с позиции i.
+func (v *bitVector) nextZero(i int) int {
+	w := i / 64
+	zeros := ^v.words[w] >> (i % 64) << (i % 64)
+	for zeros == 0 {
+		w++
+		zeros = ^v.words[w]
+	}
+
+	return w*64 + bits.TrailingZeros64(zeros)
+}
@@ -0,0 +1,39 @@
+package louds_trie
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBitVector_RankSelect(t *testing.T) {
+	for _, size := range []int{1, 63, 64, 65, 511, 512, 513, 5000} {
+		var v bitVector
+		bits := make([]bool, size)
+		for i := range bits {
+			bits[i] = rand.Intn(3) > 0
+			v.append(bits[i])
+		}
+		v.build()
+
+		ones, zeros := 0, 0
+		for i, bit := range bits {
+			if got := v.rank1(i); got != ones {
+				t.Fatalf("size %d: rank1(%d) = %d, want %d", size, i, got, ones)
+			}
+			if v.get(i) != bit {
+				t.Fatalf("size %d: get(%d) = %v, want %v", size, i, v.get(i), bit)
+			}
+			if bit {
+				ones++
+				continue
+			}
+			zeros++
+			if got := v.select0(zeros); got != i {
+				t.Fatalf("size %d: select0(%d) = %d, want %d", size, zeros, got, i)
+			}
+		}
+		if got := v.rank1(size); got != ones {
+			t.Fatalf("size %d: rank1(%d) = %d, want %d", size, size, got, ones)
+		}
+	}
+}
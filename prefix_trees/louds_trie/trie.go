@@ -0,0 +1,257 @@
+// Package louds_trie содержит статическое сжатое (succinct) префиксное дерево
+// в представлении LOUDS (level-order unary degree sequence).
+//
+// Структура дерева хранится в битовом векторе: узлы перебираются в ширину,
+// и для каждого узла записывается столько единиц, сколько у него дочерних узлов,
+// и завершающий ноль. Переход к дочерним узлам выполняется операциями rank и select
+// над этим вектором, поэтому на узел тратится около двух бит структуры, один байт
+// метки и один бит признака значения вместо указателей и масок.
+package louds_trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+// ErrUnsortedKeys - ключи для построения дерева не упорядочены по возрастанию
+// или повторяются.
+var ErrUnsortedKeys = errors.New("keys are not sorted")
+
+// Trie неизменяемое префиксное дерево в представлении LOUDS.
+//
+// Узлы нумеруются в порядке обхода в ширину, корень имеет номер 0. Дочерние узлы
+// одного родителя имеют последовательные номера и упорядочены по байту метки.
+// Дерево безопасно для одновременного чтения из нескольких горутин.
+type Trie[V any] struct {
+	// LOUDS-последовательность с префиксом "10" для фиктивного родителя корня
+	louds bitVector
+	// признаки наличия значения в узлах
+	terminal bitVector
+	// байты меток узлов (у корня метки нет)
+	labels []byte
+	// значения в порядке номеров узлов
+	values []V
+}
+
+// NewFromSorted строит дерево из ключей, упорядоченных по возрастанию байт,
+// и соответствующих им значений. Если ключи не упорядочены или повторяются,
+// возвращается ошибка ErrUnsortedKeys.
+func NewFromSorted[K prefix_trees.Key, V any](keys []K, values []V) (*Trie[V], error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("keys count %d does not match values count %d", len(keys), len(values))
+	}
+	for i := 1; i < len(keys); i++ {
+		if string(keys[i-1]) >= string(keys[i]) {
+			return nil, fmt.Errorf("%w: %q at position %d", ErrUnsortedKeys, keys[i], i)
+		}
+	}
+
+	return build(keys, values), nil
+}
+
+// NewFromTrie строит дерево из всех значений дерева trie, перебирая их методом Walk.
+// Порядок перебора не важен: ключи упорядочиваются при построении.
+func NewFromTrie[K prefix_trees.Key, V any](trie prefix_trees.Reader[K, V]) (*Trie[V], error) {
+	keys := make([]string, 0, trie.Count())
+	values := make([]V, 0, trie.Count())
+	err := trie.Walk(func(key K, value V) error {
+		keys = append(keys, string(key))
+		values = append(values, value)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !sort.StringsAreSorted(keys) {
+		sort.Sort(&byKeys[V]{keys: keys, values: values})
+	}
+
+	return build(keys, values), nil
+}
+
+// build строит дерево из упорядоченных уникальных ключей. Узлы обрабатываются
+// в порядке обхода в ширину, каждый узел описывается диапазоном ключей
+// с общим префиксом длины depth.
+func build[K prefix_trees.Key, V any](keys []K, values []V) *Trie[V] {
+	t := &Trie[V]{labels: []byte{0}, values: make([]V, 0, len(keys))}
+	// фиктивный родитель корня
+	t.louds.append(true)
+	t.louds.append(false)
+
+	type span struct {
+		lo, hi, depth int
+	}
+	queue := []span{{lo: 0, hi: len(keys), depth: 0}}
+
+	for head := 0; head < len(queue); head++ {
+		s := queue[head]
+		// обработанная часть очереди больше не нужна
+		if head > 1024 && head > len(queue)/2 {
+			queue = append(queue[:0], queue[head:]...)
+			head = 0
+		}
+
+		lo := s.lo
+		if lo < s.hi && len(keys[lo]) == s.depth {
+			t.terminal.append(true)
+			t.values = append(t.values, values[lo])
+			lo++
+		} else {
+			t.terminal.append(false)
+		}
+
+		// дочерние узлы - группы ключей с одинаковым байтом на позиции depth
+		for lo < s.hi {
+			k := keys[lo][s.depth]
+			hi := lo + 1
+			for hi < s.hi && keys[hi][s.depth] == k {
+				hi++
+			}
+			t.louds.append(true)
+			t.labels = append(t.labels, k)
+			queue = append(queue, span{lo: lo, hi: hi, depth: s.depth + 1})
+			lo = hi
+		}
+		t.louds.append(false)
+	}
+
+	t.louds.build()
+	t.terminal.build()
+
+	return t
+}
+
+func (t *Trie[V]) Count() int {
+	return len(t.values)
+}
+
+func (t *Trie[V]) Get(key []byte) V {
+	v, _ := t.Find(key)
+
+	return v
+}
+
+func (t *Trie[V]) Find(key []byte) (V, bool) {
+	var zero V
+
+	node, ok := t.descend(key)
+	if !ok || !t.terminal.get(node) {
+		return zero, false
+	}
+
+	return t.values[t.terminal.rank1(node)], true
+}
+
+// Walk перебирает дерево в порядке возрастания ключей и для каждого значения
+// вызывает функцию f. Слайс ключа используется повторно и должен быть скопирован,
+// если он нужен после возврата из f.
+func (t *Trie[V]) Walk(f func(key []byte, value V) error) error {
+	return t.WalkPrefix(nil, f)
+}
+
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (t *Trie[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	node, ok := t.descend(prefix)
+	if !ok {
+		return nil
+	}
+
+	key := make([]byte, len(prefix), len(prefix)+32)
+	copy(key, prefix)
+
+	return t.walk(node, key, f)
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (t *Trie[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(t.WalkPrefix, prefix, limit)
+}
+
+func (t *Trie[V]) MarshalJSON() ([]byte, error) {
+	var data bytes.Buffer
+	if err := t.EncodeJSON(&data); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// EncodeJSON записывает дерево в w объектом JSON в порядке возрастания ключей.
+func (t *Trie[V]) EncodeJSON(w io.Writer, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Encode(w, t.Walk, options...)
+}
+
+// descend возвращает номер узла по ключу key.
+func (t *Trie[V]) descend(key []byte) (int, bool) {
+	node := 0
+
+	for _, k := range key {
+		first, count := t.children(node)
+		// дочерние узлы упорядочены по метке, поэтому используется двоичный поиск
+		i := sort.Search(count, func(i int) bool {
+			return t.labels[first+i] >= k
+		})
+		if i == count || t.labels[first+i] != k {
+			return 0, false
+		}
+		node = first + i
+	}
+
+	return node, true
+}
+
+// children возвращает номер первого дочернего узла и количество дочерних узлов.
+// Единицы дочерних узлов узла node следуют за (node+1)-м нулем последовательности,
+// а номер узла равен количеству единиц перед его единицей.
+func (t *Trie[V]) children(node int) (int, int) {
+	start := t.louds.select0(node+1) + 1
+	end := t.louds.nextZero(start)
+
+	return t.louds.rank1(start), end - start
+}
+
+func (t *Trie[V]) walk(node int, key []byte, f func(key []byte, value V) error) error {
+	if t.terminal.get(node) {
+		if err := f(key, t.values[t.terminal.rank1(node)]); err != nil {
+			return err
+		}
+	}
+
+	first, count := t.children(node)
+	for child := first; child < first+count; child++ {
+		if err := t.walk(child, append(key, t.labels[child]), f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// byKeys упорядочивает ключи вместе со значениями.
+type byKeys[V any] struct {
+	keys   []string
+	values []V
+}
+
+func (s *byKeys[V]) Len() int {
+	return len(s.keys)
+}
+
+func (s *byKeys[V]) Less(i, j int) bool {
+	return s.keys[i] < s.keys[j]
+}
+
+func (s *byKeys[V]) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
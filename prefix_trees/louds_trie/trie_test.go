@@ -0,0 +1,278 @@
+package louds_trie_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/alphabet_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/louds_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestNewFromSorted(t *testing.T) {
+	keys, values := sortedCountries()
+
+	trie, err := louds_trie.NewFromSorted(keys, values)
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(keys), trie.Count())
+	for i, key := range keys {
+		v, found := trie.Find([]byte(key))
+		assert.True(t, found, "at key: %s", key)
+		assert.Equal(t, values[i], v, "at key: %s", key)
+	}
+	for _, key := range []string{"", "A", "Bosnia", "Chadd", "Zz", "q"} {
+		_, found := trie.Find([]byte(key))
+		assert.False(t, found, "at key: %s", key)
+	}
+	assertWalkOrdered(t, keys, values, trie)
+}
+
+func TestNewFromSorted_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []string
+		values []int
+	}{
+		{name: "unsorted", keys: []string{"b", "a"}, values: []int{1, 2}},
+		{name: "duplicate", keys: []string{"a", "a"}, values: []int{1, 2}},
+		{name: "prefix after key", keys: []string{"ab", "a"}, values: []int{1, 2}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := louds_trie.NewFromSorted(test.keys, test.values)
+
+			assert.ErrorIs(t, err, louds_trie.ErrUnsortedKeys)
+		})
+	}
+
+	_, err := louds_trie.NewFromSorted[string, int]([]string{"a"}, nil)
+	assert.Error(t, err)
+}
+
+func TestNewFromTrie(t *testing.T) {
+	keys, values := sortedCountries()
+	keys = append([]string{""}, keys...)
+	values = append([]int{-1}, values...)
+
+	t.Run("byte trie", func(t *testing.T) {
+		items := &byte_trie.Array[int]{}
+		for i, key := range keys {
+			items.Put([]byte(key), values[i])
+		}
+
+		trie, err := louds_trie.NewFromTrie[[]byte, int](items)
+
+		assert.NoError(t, err)
+		assertWalkOrdered(t, keys, values, trie)
+	})
+	t.Run("alphabet trie", func(t *testing.T) {
+		// перебор в порядке алфавита отличается от порядка байт
+		items := alphabet_trie.NewArray64[int](trietest.Alphabet)
+		for i, key := range keys {
+			items.Put(key, values[i])
+		}
+
+		trie, err := louds_trie.NewFromTrie[string, int](items)
+
+		assert.NoError(t, err)
+		assertWalkOrdered(t, keys, values, trie)
+	})
+}
+
+func TestTrie_WalkPrefix(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	for i, country := range fixtures.Countries {
+		items.Put([]byte(country), i)
+	}
+	trie, err := louds_trie.NewFromTrie[[]byte, int](items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes := []string{"", "B", "Bo", "Bosnia", "Bosnia and Herzegovina", "Bosnia and Herzegovina!", "Z", "Zz", "q"}
+	for _, prefix := range prefixes {
+		want := items.KeysWithPrefix([]byte(prefix), 0)
+		got := trie.KeysWithPrefix([]byte(prefix), 0)
+		assert.Equal(t, want, got, "at prefix: %s", prefix)
+	}
+	assert.Len(t, trie.KeysWithPrefix([]byte("B"), 3), 3)
+}
+
+func TestTrie_BinaryKeys(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	// узлы со всеми значениями байт проверяют переходы через границы слов вектора
+	for i := 0; i < 256; i++ {
+		items.Put([]byte{byte(i)}, i)
+		items.Put([]byte{0xFF, byte(i), 0x00}, -i)
+	}
+	for i := 0; i < 10000; i++ {
+		key := make([]byte, rand.Intn(8))
+		rand.Read(key)
+		items.Put(key, i)
+	}
+
+	trie, err := louds_trie.NewFromTrie[[]byte, int](items)
+
+	assert.NoError(t, err)
+	assert.Equal(t, items.Count(), trie.Count())
+	_ = items.Walk(func(key []byte, value int) error {
+		v, found := trie.Find(key)
+		assert.True(t, found, "at key: %v", key)
+		assert.Equal(t, value, v, "at key: %v", key)
+		return nil
+	})
+}
+
+func TestTrie_Empty(t *testing.T) {
+	trie, err := louds_trie.NewFromSorted[string, int](nil, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, trie.Count())
+	_, found := trie.Find(nil)
+	assert.False(t, found)
+	assert.Empty(t, trie.KeysWithPrefix(nil, 0))
+}
+
+func TestTrie_MarshalJSON(t *testing.T) {
+	trie, err := louds_trie.NewFromSorted([]string{"alpha", "beta"}, []int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := trie.MarshalJSON()
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"alpha":1,"beta":2}`, string(data))
+}
+
+func BenchmarkTrie_Fill(b *testing.B) {
+	cities := sortedCities(b)
+	values := make([]int, len(cities))
+	for n := range values {
+		values[n] = n + 1
+	}
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := louds_trie.NewFromSorted(cities, values); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTrie_Get(b *testing.B) {
+	cities := sortedCities(b)
+	values := make([]int, len(cities))
+	for n := range values {
+		values[n] = n + 1
+	}
+	t, err := louds_trie.NewFromSorted(cities, values)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	benchmarks := []struct {
+		name     string
+		cityName string
+	}{
+		{
+			name:     "short name",
+			cityName: "Adville",
+		},
+		{
+			name:     "long name",
+			cityName: "Advocate Lutheran General Childrens Hospital",
+		},
+		{
+			name:     "long unique suffix",
+			cityName: "Advocate Services Medical Transportation",
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, found := t.Find([]byte(bm.cityName))
+				if !found {
+					b.Fatal("element not found")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTrie_KeysWithPrefix(b *testing.B) {
+	cities := sortedCities(b)
+	t, err := louds_trie.NewFromSorted(cities, make([]int, len(cities)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for _, prefix := range []string{"S", "San", "San Francisco"} {
+		b.Run(prefix, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				keys := t.KeysWithPrefix([]byte(prefix), 10)
+				if len(keys) == 0 {
+					b.Fatal("elements not found")
+				}
+			}
+		})
+	}
+}
+
+// sortedCities возвращает упорядоченные названия городов без повторов.
+func sortedCities(b *testing.B) []string {
+	cities := fixtures.CitiesT(b)
+	sort.Strings(cities)
+	unique := cities[:0]
+	for i, city := range cities {
+		if i == 0 || city != cities[i-1] {
+			unique = append(unique, city)
+		}
+	}
+
+	return unique
+}
+
+// sortedCountries возвращает упорядоченные названия стран без повторов и их номера.
+func sortedCountries() ([]string, []int) {
+	keys := append([]string(nil), fixtures.Countries...)
+	sort.Strings(keys)
+	unique := keys[:0]
+	for i, key := range keys {
+		if i == 0 || key != keys[i-1] {
+			unique = append(unique, key)
+		}
+	}
+	values := make([]int, len(unique))
+	for i := range values {
+		values[i] = i + 1
+	}
+
+	return unique, values
+}
+
+func assertWalkOrdered(t *testing.T, keys []string, values []int, trie *louds_trie.Trie[int]) {
+	t.Helper()
+
+	var gotKeys []string
+	var gotValues []int
+	err := trie.Walk(func(key []byte, value int) error {
+		gotKeys = append(gotKeys, string(key))
+		gotValues = append(gotValues, value)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, keys, gotKeys)
+	assert.Equal(t, values, gotValues)
+}
@@ -0,0 +1,139 @@
+package mapped_trie
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"sort"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// Build записывает в w дерево в формате пакета, построенное из всех значений
+// дерева trie. Значения кодируются кодеком codec, тот же кодек нужно передать
+// в функции Open и New.
+//
+// Ключи перебираются методом Walk и сортируются, поэтому подходит любая реализация
+// дерева, в том числе с порядком ключей по алфавиту. Дерево строится в памяти
+// целиком и записывается одним вызовом.
+func Build[K prefix_trees.Key, V any](
+	w io.Writer,
+	trie prefix_trees.Reader[K, V],
+	codec prefix_trees.ValueCodec[V],
+) error {
+	if codec == nil {
+		return errNoCodec
+	}
+
+	b := builder{}
+	err := trie.Walk(func(key K, value V) error {
+		var err error
+		start := len(b.values)
+		b.values, err = codec.AppendValue(b.values, value)
+		if err != nil {
+			return err
+		}
+		b.entries = append(b.entries, entry{
+			key:   string(key),
+			start: start,
+			end:   len(b.values),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(b.entries, func(i, j int) bool {
+		return b.entries[i].key < b.entries[j].key
+	})
+
+	b.data = make([]byte, headerSize, headerSize+len(b.values)+len(b.entries)*16)
+	root := b.build(b.entries, 0)
+
+	copy(b.data, magic)
+	b.data[len(magic)] = version
+	binary.LittleEndian.PutUint64(b.data[8:], uint64(len(b.entries)))
+	binary.LittleEndian.PutUint64(b.data[16:], root)
+	binary.LittleEndian.PutUint32(b.data[24:], crc32.ChecksumIEEE(b.data[headerSize:]))
+
+	_, err = w.Write(b.data)
+
+	return err
+}
+
+// entry - ключ и границы его закодированного значения в буфере значений.
+type entry struct {
+	key        string
+	start, end int
+}
+
+type builder struct {
+	entries []entry
+	values  []byte
+	data    []byte
+}
+
+// build записывает узел для упорядоченных ключей entries с общим префиксом
+// длины depth и возвращает смещение узла. Дочерние узлы записываются раньше.
+func (b *builder) build(entries []entry, depth int) uint64 {
+	// метка продолжается, пока ни один ключ не заканчивается и у всех ключей
+	// совпадает следующий байт (для упорядоченных ключей достаточно сравнить
+	// первый и последний)
+	var label string
+	if len(entries) > 0 {
+		first, last := entries[0].key, entries[len(entries)-1].key
+		end := depth
+		for end < len(first) && first[end] == last[end] {
+			end++
+		}
+		label = first[depth:end]
+	}
+	depth += len(label)
+
+	var value *entry
+	if len(entries) > 0 && len(entries[0].key) == depth {
+		value = &entries[0]
+		entries = entries[1:]
+	}
+
+	// дочерние узлы для групп ключей с одинаковым байтом после метки
+	var mask [4]uint64
+	var offsets []uint64
+	for len(entries) > 0 {
+		k := entries[0].key[depth]
+		i := sort.Search(len(entries), func(i int) bool {
+			return entries[i].key[depth] > k
+		})
+		mask[k>>6] |= 1 << (k & 0x3F)
+		offsets = append(offsets, b.build(entries[:i], depth+1))
+		entries = entries[i:]
+	}
+
+	offset := uint64(len(b.data))
+	flags := byte(0)
+	if value != nil {
+		flags |= flagValue
+	}
+	if len(offsets) > 0 {
+		flags |= flagChildren
+	}
+	b.data = append(b.data, flags)
+	b.data = binary.AppendUvarint(b.data, uint64(len(label)))
+	b.data = append(b.data, label...)
+
+	if value != nil {
+		b.data = binary.AppendUvarint(b.data, uint64(value.end-value.start))
+		b.data = append(b.data, b.values[value.start:value.end]...)
+	}
+	if len(offsets) > 0 {
+		for _, word := range mask {
+			b.data = binary.LittleEndian.AppendUint64(b.data, word)
+		}
+		for _, child := range offsets {
+			b.data = binary.LittleEndian.AppendUint64(b.data, child)
+		}
+	}
+
+	return offset
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package mapped_trie
+
+import (
+	"os"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// Open читает файл path в память и создает дерево поверх прочитанных байт.
+// На платформах без поддержки mmap файл читается целиком.
+func Open[V any](path string, codec prefix_trees.ValueCodec[V]) (*Trie[V], error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(data, codec)
+}
@@ -0,0 +1,47 @@
+//go:build unix
+
+package mapped_trie
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// Open отображает файл path в память только для чтения и создает дерево поверх
+// отображенных байт. Страницы файла загружаются операционной системой по мере
+// обращения к ним и разделяются между процессами, открывшими тот же файл.
+// После использования дерево необходимо закрыть методом Close.
+func Open[V any](path string, codec prefix_trees.ValueCodec[V]) (*Trie[V], error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return nil, fmt.Errorf("%w: empty file", prefix_trees.ErrUnsupportedFormat)
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mmap %s: %w", path, err)
+	}
+
+	t, err := New(data, codec)
+	if err != nil {
+		_ = syscall.Munmap(data)
+		return nil, err
+	}
+	t.close = func() error {
+		return syscall.Munmap(data)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,386 @@
+// Package mapped_trie содержит префиксное дерево только для чтения в формате,
+// который отображается в память (mmap) и используется без десериализации.
+//
+// Дерево строится функцией Build из любой реализации prefix_trees.Reader и
+// открывается функцией Open. Поиск читает битовые маски и смещения дочерних узлов
+// прямо из отображенных байт, поэтому дерево не занимает память в куче, не требует
+// сканирования сборщиком мусора, а страницы файла разделяются между процессами.
+//
+// Формат файла (все числа в порядке little endian):
+//
+//	заголовок (32 байта):
+//	  magic    4 байта "PTRM"
+//	  version  1 байт
+//	  reserved 3 байта
+//	  count    uint64, количество значений
+//	  root     uint64, смещение корневого узла
+//	  crc32    uint32, контрольная сумма IEEE всех байт после заголовка
+//	  reserved 4 байта
+//	узлы:
+//	  flags    1 байт: 1 - есть значение, 2 - есть дочерние узлы
+//	  label    uvarint длина и байты метки (сжатая цепочка узлов с одним дочерним узлом)
+//	  value    uvarint длина и байты закодированного значения (если есть)
+//	  mask     32 байта, битовая маска байт дочерних узлов (если есть)
+//	  children uint64 смещения дочерних узлов в порядке возрастания байт (если есть)
+//
+// Дочерние узлы записываются раньше родительских, корневой узел - последним.
+package mapped_trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/bits"
+
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/internal/jsonfmt"
+)
+
+const (
+	magic      = "PTRM"
+	version    = 1
+	headerSize = 32
+	maskSize   = 32
+)
+
+// флаги узла
+const (
+	flagValue    = 1
+	flagChildren = 2
+)
+
+// errNoCodec - не указан кодек значений.
+var errNoCodec = errors.New("value codec is not specified")
+
+// Trie префиксное дерево только для чтения поверх байт в формате пакета.
+// Дерево безопасно для одновременного чтения из нескольких горутин.
+type Trie[V any] struct {
+	data  []byte
+	count int
+	root  uint64
+	codec prefix_trees.ValueCodec[V]
+	// функция освобождения отображения (для деревьев, открытых функцией Open)
+	close func() error
+}
+
+// New создает дерево поверх данных data, построенных функцией Build. Данные
+// не копируются и не должны изменяться, пока используется дерево. Значения
+// декодируются кодеком codec, указанным при построении.
+//
+// Проверяется только заголовок, остальные данные проверяются при чтении узлов:
+// для поврежденных данных методы поиска сообщают об отсутствии ключа, а методы
+// перебора возвращают ошибку prefix_trees.ErrCorruptedData. Для проверки
+// контрольной суммы всех данных следует вызвать метод Verify.
+func New[V any](data []byte, codec prefix_trees.ValueCodec[V]) (*Trie[V], error) {
+	if codec == nil {
+		return nil, errNoCodec
+	}
+	if len(data) < headerSize || string(data[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: invalid header", prefix_trees.ErrUnsupportedFormat)
+	}
+	if data[len(magic)] != version {
+		return nil, fmt.Errorf("%w: version %d", prefix_trees.ErrUnsupportedFormat, data[len(magic)])
+	}
+
+	root := binary.LittleEndian.Uint64(data[16:])
+	if root < headerSize || root >= uint64(len(data)) {
+		return nil, fmt.Errorf("%w: invalid root offset", prefix_trees.ErrCorruptedData)
+	}
+
+	return &Trie[V]{
+		data:  data,
+		count: int(binary.LittleEndian.Uint64(data[8:])),
+		root:  root,
+		codec: codec,
+	}, nil
+}
+
+// Verify проверяет контрольную сумму данных. Метод читает данные целиком.
+func (t *Trie[V]) Verify() error {
+	if crc32.ChecksumIEEE(t.data[headerSize:]) != binary.LittleEndian.Uint32(t.data[24:]) {
+		return fmt.Errorf("%w: checksum mismatch", prefix_trees.ErrCorruptedData)
+	}
+
+	return nil
+}
+
+// Close освобождает отображение файла в память. После закрытия дерево
+// использовать нельзя. Для деревьев, созданных функцией New, ничего не делает.
+func (t *Trie[V]) Close() error {
+	if t.close == nil {
+		return nil
+	}
+	err := t.close()
+	t.close = nil
+	t.data = nil
+
+	return err
+}
+
+func (t *Trie[V]) Count() int {
+	return t.count
+}
+
+func (t *Trie[V]) Get(key []byte) V {
+	v, _ := t.Find(key)
+
+	return v
+}
+
+// Find возвращает значение по ключу. Если значение не удалось декодировать
+// или данные на пути к ключу повреждены, то возвращается флаг отсутствия.
+func (t *Trie[V]) Find(key []byte) (V, bool) {
+	var zero V
+	data, ok := t.FindRaw(key)
+	if !ok {
+		return zero, false
+	}
+
+	value, err := t.codec.DecodeValue(data)
+	if err != nil {
+		return zero, false
+	}
+
+	return value, true
+}
+
+// FindRaw возвращает закодированное значение по ключу без копирования.
+// Возвращаемый слайс ссылается на данные дерева и не должен изменяться.
+func (t *Trie[V]) FindRaw(key []byte) ([]byte, bool) {
+	n, err := t.node(t.root)
+	if err != nil {
+		return nil, false
+	}
+
+	for {
+		// метка узла должна быть префиксом оставшейся части ключа
+		if len(key) < len(n.label) || string(key[:len(n.label)]) != string(n.label) {
+			return nil, false
+		}
+		key = key[len(n.label):]
+
+		if len(key) == 0 {
+			return n.value, n.flags&flagValue != 0
+		}
+
+		child, ok, err := t.child(&n, key[0])
+		if !ok || err != nil {
+			return nil, false
+		}
+		n = child
+		key = key[1:]
+	}
+}
+
+// Walk перебирает дерево в порядке возрастания ключей и для каждого значения
+// вызывает функцию f. Слайс ключа используется повторно и должен быть скопирован,
+// если он нужен после возврата из f.
+func (t *Trie[V]) Walk(f func(key []byte, value V) error) error {
+	return t.WalkPrefix(nil, f)
+}
+
+// WalkPrefix перебирает в порядке возрастания все значения, ключи которых начинаются
+// с префикса prefix (включая сам префикс), и для каждого из них вызывает функцию f.
+func (t *Trie[V]) WalkPrefix(prefix []byte, f func(key []byte, value V) error) error {
+	key := make([]byte, 0, 64)
+	n, err := t.node(t.root)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if len(prefix) <= len(n.label) {
+			// префикс закончился внутри метки узла
+			if string(n.label[:len(prefix)]) != string(prefix) {
+				return nil
+			}
+			key = append(key, n.label...)
+
+			return t.walk(n, key, f)
+		}
+		if string(prefix[:len(n.label)]) != string(n.label) {
+			return nil
+		}
+		prefix = prefix[len(n.label):]
+		key = append(key, n.label...)
+
+		child, ok, err := t.child(&n, prefix[0])
+		if !ok || err != nil {
+			return err
+		}
+		key = append(key, prefix[0])
+		prefix = prefix[1:]
+		n = child
+	}
+}
+
+// KeysWithPrefix возвращает в порядке возрастания ключи, начинающиеся с префикса prefix.
+// Если limit больше нуля, то возвращается не более limit ключей.
+func (t *Trie[V]) KeysWithPrefix(prefix []byte, limit int) [][]byte {
+	return prefix_trees.KeysWithPrefix(t.WalkPrefix, prefix, limit)
+}
+
+func (t *Trie[V]) MarshalJSON() ([]byte, error) {
+	var data bytes.Buffer
+	if err := t.EncodeJSON(&data); err != nil {
+		return nil, err
+	}
+
+	return data.Bytes(), nil
+}
+
+// EncodeJSON записывает дерево в w объектом JSON в порядке возрастания ключей.
+func (t *Trie[V]) EncodeJSON(w io.Writer, options ...prefix_trees.JSONOption) error {
+	return jsonfmt.Encode(w, t.Walk, options...)
+}
+
+// walk перебирает поддерево узла n, ключ которого (с учетом метки) равен key.
+func (t *Trie[V]) walk(n node, key []byte, f func(key []byte, value V) error) error {
+	if n.flags&flagValue != 0 {
+		value, err := t.codec.DecodeValue(n.value)
+		if err != nil {
+			return fmt.Errorf("decode value at key %q: %w", key, err)
+		}
+		if err := f(key, value); err != nil {
+			return err
+		}
+	}
+	if n.flags&flagChildren == 0 {
+		return nil
+	}
+
+	i := 0
+	for hi := 0; hi < 4; hi++ {
+		word := n.word(hi)
+		for word != 0 {
+			k := byte(hi<<6 | bits.TrailingZeros64(word))
+			word &= word - 1
+
+			child, err := t.childAt(&n, i)
+			if err != nil {
+				return err
+			}
+			i++
+			next := append(append(key, k), child.label...)
+			if err := t.walk(child, next, f); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// node - узел дерева, прочитанный из данных. Поля ссылаются на данные дерева.
+type node struct {
+	// смещение узла в данных
+	at       uint64
+	flags    byte
+	label    []byte
+	value    []byte
+	mask     []byte
+	children []byte
+}
+
+// node читает узел по смещению offset. Все длины и размер массива смещений
+// дочерних узлов проверяются по границам данных.
+func (t *Trie[V]) node(offset uint64) (node, error) {
+	if offset < headerSize || offset >= uint64(len(t.data)) {
+		return node{}, corruptedNode(offset)
+	}
+	data := t.data[offset:]
+	n := node{at: offset, flags: data[0]}
+	data = data[1:]
+
+	var ok bool
+	if n.label, data, ok = readBytes(data); !ok {
+		return node{}, corruptedNode(offset)
+	}
+	if n.flags&flagValue != 0 {
+		if n.value, data, ok = readBytes(data); !ok {
+			return node{}, corruptedNode(offset)
+		}
+	}
+	if n.flags&flagChildren != 0 {
+		if len(data) < maskSize {
+			return node{}, corruptedNode(offset)
+		}
+		n.mask = data[:maskSize]
+		count := 0
+		for hi := 0; hi < 4; hi++ {
+			count += bits.OnesCount64(n.word(hi))
+		}
+		if count == 0 || len(data)-maskSize < count*8 {
+			return node{}, corruptedNode(offset)
+		}
+		n.children = data[maskSize : maskSize+count*8]
+	}
+
+	return n, nil
+}
+
+// child возвращает дочерний узел узла n по байту k.
+func (t *Trie[V]) child(n *node, k byte) (node, bool, error) {
+	index, ok := n.index(k)
+	if !ok {
+		return node{}, false, nil
+	}
+	child, err := t.childAt(n, index)
+
+	return child, true, err
+}
+
+// childAt возвращает дочерний узел узла n с порядковым номером index. Дочерние
+// узлы записываются раньше родительских, поэтому смещение, не меньшее смещения
+// родителя, означает поврежденные данные (и защищает перебор от циклов).
+func (t *Trie[V]) childAt(n *node, index int) (node, error) {
+	offset := binary.LittleEndian.Uint64(n.children[index*8:])
+	if offset >= n.at {
+		return node{}, corruptedNode(offset)
+	}
+
+	return t.node(offset)
+}
+
+func (n *node) word(hi int) uint64 {
+	return binary.LittleEndian.Uint64(n.mask[hi*8:])
+}
+
+// index возвращает порядковый номер дочернего узла по байту k. Порядковый номер
+// равен количеству установленных битов маски, меньших k.
+func (n *node) index(k byte) (int, bool) {
+	if n.flags&flagChildren == 0 {
+		return 0, false
+	}
+
+	hi, lo := int(k>>6), k&0x3F
+	word := n.word(hi)
+	if word&(1<<lo) == 0 {
+		return 0, false
+	}
+
+	index := bits.OnesCount64(word & ^(uint64(0xFFFFFFFFFFFFFFFF) << lo))
+	for i := 0; i < hi; i++ {
+		index += bits.OnesCount64(n.word(i))
+	}
+
+	return index, true
+}
+
+// readBytes читает байты с длиной в формате uvarint и возвращает их и остаток данных.
+func readBytes(data []byte) ([]byte, []byte, bool) {
+	size, m := binary.Uvarint(data)
+	if m <= 0 || size > uint64(len(data)-m) {
+		return nil, nil, false
+	}
+	end := m + int(size)
+
+	return data[m:end], data[end:], true
+}
+
+func corruptedNode(offset uint64) error {
+	return fmt.Errorf("%w: invalid node at offset %d", prefix_trees.ErrCorruptedData, offset)
+}
@@ -0,0 +1,391 @@
+package mapped_trie_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/prefix_trees/alphabet_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_shard_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_suffix_trie"
+	"github.com/strider2038/algos/prefix_trees/byte_trie"
+	"github.com/strider2038/algos/prefix_trees/mapped_trie"
+	"github.com/strider2038/algos/prefix_trees/trietest"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestBuild(t *testing.T) {
+	m := map[string]int{"": -1}
+	for i, country := range fixtures.Countries {
+		m[country] = i
+	}
+	tests := []struct {
+		name  string
+		build func(w *bytes.Buffer) error
+	}{
+		{
+			name: "alphabet trie",
+			build: func(w *bytes.Buffer) error {
+				trie := alphabet_trie.NewArray64[int](trietest.Alphabet)
+				for key, value := range m {
+					trie.Put(key, value)
+				}
+				return mapped_trie.Build[string, int](w, trie, prefix_trees.VarintCodec[int]{})
+			},
+		},
+		{
+			name: "byte trie",
+			build: func(w *bytes.Buffer) error {
+				return mapped_trie.Build[[]byte, int](w, fill(&byte_trie.Array[int]{}, m), prefix_trees.VarintCodec[int]{})
+			},
+		},
+		{
+			name: "byte shard trie",
+			build: func(w *bytes.Buffer) error {
+				return mapped_trie.Build[[]byte, int](w, fill(&byte_shard_trie.Array[int]{}, m), prefix_trees.VarintCodec[int]{})
+			},
+		},
+		{
+			name: "byte suffix trie",
+			build: func(w *bytes.Buffer) error {
+				return mapped_trie.Build[[]byte, int](w, fill(&byte_suffix_trie.Array[int]{}, m), prefix_trees.VarintCodec[int]{})
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var data bytes.Buffer
+			if err := test.build(&data); err != nil {
+				t.Fatal(err)
+			}
+
+			trie, err := mapped_trie.New[int](data.Bytes(), prefix_trees.VarintCodec[int]{})
+
+			assert.NoError(t, err)
+			assert.NoError(t, trie.Verify())
+			assert.Equal(t, len(m), trie.Count())
+			assertWalkOrdered(t, m, trie)
+			for key, value := range m {
+				v, found := trie.Find([]byte(key))
+				assert.True(t, found, "at key: %s", key)
+				assert.Equal(t, value, v, "at key: %s", key)
+			}
+			for _, key := range []string{"A", "Bosnia", "Chadd", "Zz", "Bosnia and Herzegovina!"} {
+				_, found := trie.Find([]byte(key))
+				assert.False(t, found, "at key: %s", key)
+			}
+		})
+	}
+}
+
+func TestTrie_WalkPrefix(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	for i, country := range fixtures.Countries {
+		items.Put([]byte(country), i)
+	}
+	trie := build(t, items)
+
+	prefixes := []string{"", "B", "Bo", "Bosnia", "Bosnia and", "Bosnia and Herzegovina", "Bosnia and Herzegovina!", "Z", "Zz", "q"}
+	for _, prefix := range prefixes {
+		want := items.KeysWithPrefix([]byte(prefix), 0)
+		got := trie.KeysWithPrefix([]byte(prefix), 0)
+		assert.Equal(t, want, got, "at prefix: %s", prefix)
+	}
+	assert.Len(t, trie.KeysWithPrefix([]byte("B"), 3), 3)
+}
+
+func TestTrie_BinaryKeys(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	// ключи со всеми значениями байт, в том числе общие префиксы с разветвлениями
+	for i := 0; i < 256; i++ {
+		items.Put([]byte{byte(i)}, i)
+		items.Put([]byte{0xFF, byte(i), 0x00}, -i)
+	}
+	for i := 0; i < 1000; i++ {
+		key := make([]byte, rand.Intn(8))
+		rand.Read(key)
+		items.Put(key, i)
+	}
+
+	trie := build(t, items)
+
+	assert.Equal(t, items.Count(), trie.Count())
+	_ = items.Walk(func(key []byte, value int) error {
+		v, found := trie.Find(key)
+		assert.True(t, found, "at key: %v", key)
+		assert.Equal(t, value, v, "at key: %v", key)
+		return nil
+	})
+}
+
+func TestTrie_Empty(t *testing.T) {
+	trie := build(t, &byte_trie.Array[int]{})
+
+	_, found := trie.Find(nil)
+	assert.False(t, found)
+	assert.Equal(t, 0, trie.Count())
+	assert.Empty(t, trie.KeysWithPrefix(nil, 0))
+}
+
+func TestTrie_MarshalJSON(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	items.Put([]byte("alpha"), 1)
+	items.Put([]byte("beta"), 2)
+	trie := build(t, items)
+
+	data, err := trie.MarshalJSON()
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"alpha":1,"beta":2}`, string(data))
+}
+
+func TestOpen(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	for i, country := range fixtures.Countries {
+		items.Put([]byte(country), i)
+	}
+	var data bytes.Buffer
+	if err := mapped_trie.Build[[]byte, int](&data, items, prefix_trees.VarintCodec[int]{}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "countries.trie")
+	if err := os.WriteFile(path, data.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	trie, err := mapped_trie.Open[int](path, prefix_trees.VarintCodec[int]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trie.Close()
+
+	assert.NoError(t, trie.Verify())
+	assert.Equal(t, items.Count(), trie.Count())
+	assert.Equal(t, items.Get([]byte("Chad")), trie.Get([]byte("Chad")))
+}
+
+func TestOpen_Errors(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	items.Put([]byte("alpha"), 1)
+	items.Put([]byte("beta"), 2)
+	var data bytes.Buffer
+	if err := mapped_trie.Build[[]byte, int](&data, items, prefix_trees.VarintCodec[int]{}); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	_, err := mapped_trie.Open[int](filepath.Join(dir, "missing.trie"), prefix_trees.VarintCodec[int]{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(dir, "invalid.trie")
+	_ = os.WriteFile(path, []byte("not a trie"), 0o600)
+	_, err = mapped_trie.Open[int](path, prefix_trees.VarintCodec[int]{})
+	assert.ErrorIs(t, err, prefix_trees.ErrUnsupportedFormat)
+
+	corrupted := data.Bytes()
+	corrupted[len(corrupted)-1] ^= 0xFF
+	trie, err := mapped_trie.New[int](corrupted, prefix_trees.VarintCodec[int]{})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, trie.Verify(), prefix_trees.ErrCorruptedData)
+}
+
+func TestNew_NilCodec(t *testing.T) {
+	var data bytes.Buffer
+	err := mapped_trie.Build[[]byte, int](&data, &byte_trie.Array[int]{}, nil)
+	assert.Error(t, err)
+
+	err = mapped_trie.Build[[]byte, int](&data, &byte_trie.Array[int]{}, prefix_trees.VarintCodec[int]{})
+	assert.NoError(t, err)
+	_, err = mapped_trie.New[int](data.Bytes(), nil)
+	assert.Error(t, err)
+}
+
+func TestTrie_CorruptedData(t *testing.T) {
+	items := &byte_trie.Array[int]{}
+	for i, key := range []string{"a", "ab", "abc", "abd", "b", "ba", "bcd", "c"} {
+		items.Put([]byte(key), i)
+	}
+	var data bytes.Buffer
+	if err := mapped_trie.Build[[]byte, int](&data, items, prefix_trees.VarintCodec[int]{}); err != nil {
+		t.Fatal(err)
+	}
+	valid := data.Bytes()
+
+	t.Run("truncated", func(t *testing.T) {
+		// корневой узел записан последним, поэтому отсекается его массив смещений
+		trie, err := mapped_trie.New[int](valid[:len(valid)-1], prefix_trees.VarintCodec[int]{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = trie.Walk(func(key []byte, value int) error { return nil })
+		assert.ErrorIs(t, err, prefix_trees.ErrCorruptedData)
+		_, found := trie.Find([]byte("abc"))
+		assert.False(t, found)
+		assert.Empty(t, trie.KeysWithPrefix(nil, 0))
+	})
+	t.Run("every byte", func(t *testing.T) {
+		// повреждение любого байта узлов не должно приводить к панике
+		for i := 32; i < len(valid); i++ {
+			for _, b := range []byte{0x00, 0x7F, 0x80, 0xFF} {
+				corrupted := append([]byte(nil), valid...)
+				corrupted[i] = b
+				trie, err := mapped_trie.New[int](corrupted, prefix_trees.VarintCodec[int]{})
+				if err != nil {
+					continue
+				}
+
+				_ = trie.Walk(func(key []byte, value int) error { return nil })
+				_ = trie.KeysWithPrefix([]byte("a"), 0)
+				_, _ = trie.Find([]byte("abd"))
+				_, _ = trie.Find([]byte("bcd"))
+			}
+		}
+	})
+	t.Run("truncated at every length", func(t *testing.T) {
+		for size := 33; size < len(valid); size++ {
+			corrupted := append([]byte(nil), valid[:size]...)
+			// корневой узел переносится в начало усеченных данных
+			binary.LittleEndian.PutUint64(corrupted[16:], uint64(size-1))
+			trie, err := mapped_trie.New[int](corrupted, prefix_trees.VarintCodec[int]{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_ = trie.Walk(func(key []byte, value int) error { return nil })
+			_, _ = trie.Find([]byte("abc"))
+		}
+	})
+}
+
+func BenchmarkTrie_Build(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	t := byte_suffix_trie.Array[int]{}
+	for n, city := range cities {
+		t.Put([]byte(city), n+1)
+	}
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	var data bytes.Buffer
+	for i := 0; i < b.N; i++ {
+		data.Reset()
+		if err := mapped_trie.Build[[]byte, int](&data, &t, prefix_trees.VarintCodec[int]{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(data.Len()), "bytes")
+}
+
+func BenchmarkTrie_Get(b *testing.B) {
+	path := writeCities(b)
+	// дерево в куче больше не нужно
+	runtime.GC()
+
+	t, err := mapped_trie.Open[int](path, prefix_trees.VarintCodec[int]{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer t.Close()
+
+	b.ResetTimer()
+
+	benchmarks := []struct {
+		name     string
+		cityName string
+	}{
+		{
+			name:     "short name",
+			cityName: "Adville",
+		},
+		{
+			name:     "long name",
+			cityName: "Advocate Lutheran General Childrens Hospital",
+		},
+		{
+			name:     "long unique suffix",
+			cityName: "Advocate Services Medical Transportation",
+		},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, found := t.Find([]byte(bm.cityName))
+				if !found {
+					b.Fatal("element not found")
+				}
+			}
+		})
+	}
+}
+
+// writeCities записывает названия городов в файл и возвращает путь к нему.
+func writeCities(b *testing.B) string {
+	cities := fixtures.CitiesT(b)
+	items := &byte_suffix_trie.Array[int]{}
+	for n, city := range cities {
+		items.Put([]byte(city), n+1)
+	}
+	var data bytes.Buffer
+	if err := mapped_trie.Build[[]byte, int](&data, items, prefix_trees.VarintCodec[int]{}); err != nil {
+		b.Fatal(err)
+	}
+	path := filepath.Join(b.TempDir(), "cities.trie")
+	if err := os.WriteFile(path, data.Bytes(), 0o600); err != nil {
+		b.Fatal(err)
+	}
+
+	return path
+}
+
+func fill(trie prefix_trees.Trie[[]byte, int], m map[string]int) prefix_trees.Trie[[]byte, int] {
+	for key, value := range m {
+		trie.Put([]byte(key), value)
+	}
+
+	return trie
+}
+
+func build(t *testing.T, items prefix_trees.Reader[[]byte, int]) *mapped_trie.Trie[int] {
+	t.Helper()
+
+	var data bytes.Buffer
+	if err := mapped_trie.Build[[]byte, int](&data, items, prefix_trees.VarintCodec[int]{}); err != nil {
+		t.Fatal(err)
+	}
+	trie, err := mapped_trie.New[int](data.Bytes(), prefix_trees.VarintCodec[int]{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return trie
+}
+
+// assertWalkOrdered проверяет, что перебор дерева посещает ключи из m
+// в порядке возрастания байт.
+func assertWalkOrdered(t *testing.T, m map[string]int, trie *mapped_trie.Trie[int]) {
+	t.Helper()
+
+	want := make([]string, 0, len(m))
+	for key := range m {
+		want = append(want, key)
+	}
+	sort.Strings(want)
+
+	got := make([]string, 0, len(m))
+	err := trie.Walk(func(key []byte, value int) error {
+		got = append(got, string(key))
+		assert.Equal(t, m[string(key)], value, "at key: %s", key)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+}
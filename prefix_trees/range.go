@@ -0,0 +1,54 @@
+package prefix_trees
+
+import "bytes"
+
+// Bound - граница диапазона ключей. Нулевое значение означает отсутствие границы.
+type Bound struct {
+	key       []byte
+	inclusive bool
+	bounded   bool
+}
+
+// Inclusive создает границу диапазона, включающую ключ key.
+func Inclusive(key []byte) Bound {
+	return Bound{key: key, inclusive: true, bounded: true}
+}
+
+// Exclusive создает границу диапазона, исключающую ключ key.
+func Exclusive(key []byte) Bound {
+	return Bound{key: key, bounded: true}
+}
+
+// Unbounded возвращает отсутствующую границу диапазона.
+func Unbounded() Bound {
+	return Bound{}
+}
+
+// Range перебирает с помощью курсора c в порядке возрастания значения с ключами
+// в диапазоне от from до to и для каждого из них вызывает функцию f. Курсор должен
+// перебирать ключи в порядке возрастания байт.
+func Range[V any](c Cursor[[]byte, V], from, to Bound, f func(key []byte, value V) error) error {
+	ok := false
+	if from.bounded {
+		ok = c.Seek(from.key)
+		if ok && !from.inclusive && bytes.Equal(c.Key(), from.key) {
+			ok = c.Next()
+		}
+	} else {
+		ok = c.First()
+	}
+
+	for ; ok; ok = c.Next() {
+		if to.bounded {
+			cmp := bytes.Compare(c.Key(), to.key)
+			if cmp > 0 || cmp == 0 && !to.inclusive {
+				return nil
+			}
+		}
+		if err := f(c.Key(), c.Value()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
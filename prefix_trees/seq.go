@@ -0,0 +1,21 @@
+package prefix_trees
+
+// Seq - последовательность пар ключ-значение в виде функции перебора с сигнатурой
+// метода Walk: функция вызывает f для каждой пары и прерывается при первой ошибке,
+// которую возвращает. Метод Walk любого дерева является последовательностью ключей
+// в порядке их перебора.
+type Seq[K Key, V any] func(f func(key K, value V) error) error
+
+// SliceSeq возвращает последовательность пар keys[i], values[i]. Слайсы должны
+// иметь одинаковую длину.
+func SliceSeq[K Key, V any](keys []K, values []V) Seq[K, V] {
+	return func(f func(key K, value V) error) error {
+		for i, key := range keys {
+			if err := f(key, values[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
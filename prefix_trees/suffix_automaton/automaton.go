@@ -0,0 +1,295 @@
+// Package suffix_automaton содержит обобщенный суффиксный автомат для поиска
+// подстрок в наборе строк.
+//
+// Суффиксный автомат - минимальный автомат, распознающий все подстроки строк
+// набора. Каждое состояние соответствует классу подстрок с одинаковым множеством
+// позиций окончания, суффиксная ссылка состояния ведет в состояние самого длинного
+// суффикса его подстрок из другого класса. Количество состояний не превышает
+// удвоенной суммарной длины строк, количество переходов - утроенной.
+//
+// Строки, содержащие подстроку, определяются по дереву суффиксных ссылок:
+// подстрока состояния s оканчивается в конце префикса строки тогда и только тогда,
+// когда состояние этого префикса лежит в поддереве s. Состояния нумеруются
+// в порядке обхода дерева в глубину, поэтому номера строк префиксов поддерева
+// хранятся непрерывным диапазоном.
+package suffix_automaton
+
+import (
+	"sort"
+
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// Automaton неизменяемый обобщенный суффиксный автомат набора строк.
+//
+// Состояния нумеруются с нуля (0 - начальное состояние). Переходы состояния s
+// хранятся подряд в диапазоне [offsets[s], offsets[s+1]) массивов labels и targets
+// в порядке возрастания байт. Номера строк, содержащих подстроки состояния s
+// (с повторами), - диапазон [from[s], to[s]) массива strings. Автомат безопасен
+// для одновременного чтения из нескольких горутин.
+type Automaton struct {
+	offsets []uint32
+	labels  []byte
+	targets []uint32
+	from    []int32
+	to      []int32
+	strings []int32
+	// самая длинная подстрока, общая для всех строк
+	common []byte
+	count  int
+}
+
+// New строит автомат по строкам items. Номера строк в результатах поиска
+// совпадают с их индексами в items.
+func New[K prefix_trees.Key](items []K) *Automaton {
+	b := NewBuilder()
+	for _, item := range items {
+		b.Add([]byte(item))
+	}
+
+	return b.Finish()
+}
+
+// Count возвращает количество строк.
+func (a *Automaton) Count() int {
+	return a.count
+}
+
+// States возвращает количество состояний автомата.
+func (a *Automaton) States() int {
+	return len(a.from)
+}
+
+// Transitions возвращает количество переходов автомата.
+func (a *Automaton) Transitions() int {
+	return len(a.labels)
+}
+
+// ContainsSubstring проверяет, является ли pattern подстрокой хотя бы одной строки.
+func (a *Automaton) ContainsSubstring(pattern []byte) bool {
+	if len(pattern) == 0 {
+		return a.count > 0
+	}
+	_, ok := a.descend(pattern)
+
+	return ok
+}
+
+// Occurrences возвращает в порядке возрастания номера строк, содержащих подстроку
+// pattern. Пустая подстрока содержится во всех строках.
+func (a *Automaton) Occurrences(pattern []byte) []int {
+	if len(pattern) == 0 {
+		indices := make([]int, a.count)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+
+	s, ok := a.descend(pattern)
+	if !ok {
+		return nil
+	}
+
+	// строка встречается в диапазоне столько раз, сколько раз в ней оканчивается pattern
+	matches := a.strings[a.from[s]:a.to[s]]
+	indices := make([]int, len(matches))
+	for i, index := range matches {
+		indices[i] = int(index)
+	}
+	sort.Ints(indices)
+
+	unique := indices[:0]
+	for i, index := range indices {
+		if i == 0 || index != indices[i-1] {
+			unique = append(unique, index)
+		}
+	}
+
+	return unique
+}
+
+// LongestCommonSubstring возвращает самую длинную подстроку, которая содержится
+// во всех строках. Если таких подстрок несколько, возвращается одна из них.
+func (a *Automaton) LongestCommonSubstring() []byte {
+	return append([]byte(nil), a.common...)
+}
+
+// descend возвращает состояние, в которое автомат переходит по подстроке pattern.
+func (a *Automaton) descend(pattern []byte) (uint32, bool) {
+	if len(a.offsets) == 0 {
+		return 0, false
+	}
+
+	s := uint32(0)
+	for _, k := range pattern {
+		lo, hi := int(a.offsets[s]), int(a.offsets[s+1])
+		// переходы упорядочены по байту, поэтому используется двоичный поиск
+		i := lo + sort.Search(hi-lo, func(i int) bool {
+			return a.labels[lo+i] >= k
+		})
+		if i == hi || a.labels[i] != k {
+			return 0, false
+		}
+		s = a.targets[i]
+	}
+
+	return s, true
+}
+
+// freeze переводит автомат построителя в компактное представление.
+func freeze(b *Builder) *Automaton {
+	n := len(b.length)
+	a := &Automaton{count: len(b.ends)}
+
+	// переходы списков (включая переведенные на таблицы) - верхняя оценка количества переходов
+	a.offsets = make([]uint32, n+1)
+	a.labels = make([]byte, 0, len(b.labels))
+	a.targets = make([]uint32, 0, len(b.labels))
+	var edges []edge
+	for s := 0; s < n; s++ {
+		edges = b.edges(int32(s), edges[:0])
+		// списки переходов короткие, поэтому сортируются вставками
+		for i := 1; i < len(edges); i++ {
+			for j := i; j > 0 && edges[j].label < edges[j-1].label; j-- {
+				edges[j], edges[j-1] = edges[j-1], edges[j]
+			}
+		}
+		for _, e := range edges {
+			a.labels = append(a.labels, e.label)
+			a.targets = append(a.targets, uint32(e.target))
+		}
+		a.offsets[s+1] = uint32(len(a.labels))
+	}
+
+	// порядок обхода дерева суффиксных ссылок в глубину
+	order, position := linkTreeOrder(b.link)
+	size := make([]int32, n)
+	for i := n - 1; i >= 0; i-- {
+		s := order[i]
+		size[s]++
+		if s > 0 {
+			size[b.link[s]] += size[s]
+		}
+	}
+
+	// номера строк группируются по позициям состояний их префиксов
+	bounds := make([]int32, n+1)
+	for _, p := range b.prefixes {
+		bounds[position[p]+1]++
+	}
+	for i := 1; i <= n; i++ {
+		bounds[i] += bounds[i-1]
+	}
+	a.strings = make([]int32, len(b.prefixes))
+	next := append([]int32(nil), bounds[:n]...)
+	start := int32(0)
+	for index, end := range b.ends {
+		for _, p := range b.prefixes[start:end] {
+			a.strings[next[position[p]]] = int32(index)
+			next[position[p]]++
+		}
+		start = end
+	}
+
+	a.from = make([]int32, n)
+	a.to = make([]int32, n)
+	for s := 0; s < n; s++ {
+		a.from[s] = bounds[position[s]]
+		a.to[s] = bounds[position[s]+size[s]]
+	}
+
+	a.common = longestCommon(b)
+
+	return a
+}
+
+// linkTreeOrder возвращает состояния в порядке обхода дерева суффиксных ссылок
+// в глубину и позиции состояний в этом порядке.
+func linkTreeOrder(link []int32) (order, position []int32) {
+	n := len(link)
+	offsets := make([]int32, n+1)
+	for s := 1; s < n; s++ {
+		offsets[link[s]+1]++
+	}
+	for i := 1; i <= n; i++ {
+		offsets[i] += offsets[i-1]
+	}
+	children := make([]int32, n)
+	next := append([]int32(nil), offsets[:n]...)
+	for s := 1; s < n; s++ {
+		children[next[link[s]]] = int32(s)
+		next[link[s]]++
+	}
+
+	order = make([]int32, 0, n)
+	position = make([]int32, n)
+	stack := []int32{0}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		position[s] = int32(len(order))
+		order = append(order, s)
+		stack = append(stack, children[offsets[s]:offsets[s+1]]...)
+	}
+
+	return order, position
+}
+
+// longestCommon находит самую длинную подстроку, общую для всех строк построителя.
+//
+// Для каждого состояния подсчитывается количество строк, содержащих его подстроки:
+// от состояния каждого префикса строки выполняется подъем по суффиксным ссылкам
+// до первого состояния, уже отмеченного этой строкой.
+func longestCommon(b *Builder) []byte {
+	if len(b.ends) == 0 {
+		return nil
+	}
+
+	n := len(b.length)
+	marked := make([]int32, n)
+	for i := range marked {
+		marked[i] = -1
+	}
+	counts := make([]int32, n)
+	start := int32(0)
+	for index, end := range b.ends {
+		for _, p := range b.prefixes[start:end] {
+			for s := p; s > 0 && marked[s] != int32(index); s = b.link[s] {
+				marked[s] = int32(index)
+				counts[s]++
+			}
+		}
+		start = end
+	}
+
+	best := int32(0)
+	for s := int32(1); s < int32(n); s++ {
+		if counts[s] == int32(len(b.ends)) && b.length[s] > b.length[best] {
+			best = s
+		}
+	}
+	if best == 0 {
+		return nil
+	}
+
+	// самая длинная подстрока состояния восстанавливается по переходам, которые
+	// увеличивают длину самой длинной подстроки ровно на единицу
+	parents := make([]edge, n)
+	var edges []edge
+	for s := int32(0); s < int32(n); s++ {
+		edges = b.edges(s, edges[:0])
+		for _, e := range edges {
+			if b.length[e.target] == b.length[s]+1 {
+				parents[e.target] = edge{label: e.label, target: s}
+			}
+		}
+	}
+
+	common := make([]byte, b.length[best])
+	for s, i := best, len(common)-1; s > 0; s, i = parents[s].target, i-1 {
+		common[i] = parents[s].label
+	}
+
+	return common
+}
@@ -0,0 +1,184 @@
+package suffix_automaton_test
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees/suffix_automaton"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+func TestAutomaton_Occurrences(t *testing.T) {
+	a := suffix_automaton.New(fixtures.Countries)
+
+	assert.Equal(t, len(fixtures.Countries), a.Count())
+	for _, pattern := range []string{"land", "Islands", "a", "Korea", "ni", "Republic", "q", "zz", "Guinea-"} {
+		assert.Equal(t, naiveOccurrences(fixtures.Countries, pattern), a.Occurrences([]byte(pattern)), "at pattern: %s", pattern)
+		assert.Equal(t, len(naiveOccurrences(fixtures.Countries, pattern)) > 0, a.ContainsSubstring([]byte(pattern)), "at pattern: %s", pattern)
+	}
+	assert.Len(t, a.Occurrences(nil), len(fixtures.Countries))
+}
+
+func TestAutomaton_RandomStrings(t *testing.T) {
+	// маленький алфавит дает много повторяющихся подстрок и разделений состояний
+	items := randomStrings(300, 12, "abc")
+	a := suffix_automaton.New(items)
+
+	total := 0
+	for _, item := range items {
+		total += len(item)
+	}
+	assert.LessOrEqual(t, a.States(), 2*total+1)
+	assert.LessOrEqual(t, a.Transitions(), 3*total)
+
+	for _, item := range items {
+		for i := 0; i < len(item); i++ {
+			for j := i + 1; j <= len(item); j++ {
+				pattern := item[i:j]
+				assert.True(t, a.ContainsSubstring([]byte(pattern)), "at pattern: %s", pattern)
+			}
+		}
+	}
+	for _, pattern := range randomStrings(300, 6, "abcd") {
+		assert.Equal(t, naiveOccurrences(items, pattern), a.Occurrences([]byte(pattern)), "at pattern: %s", pattern)
+	}
+}
+
+func TestAutomaton_LongestCommonSubstring(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []string
+		want  string
+	}{
+		{name: "no strings", items: nil, want: ""},
+		{name: "single string", items: []string{"abc"}, want: "abc"},
+		{name: "common middle", items: []string{"abcde", "xbcdy", "zzbcd"}, want: "bcd"},
+		{name: "substring of another", items: []string{"banana", "nan"}, want: "nan"},
+		{name: "nothing common", items: []string{"abc", "xyz"}, want: ""},
+		{name: "empty string", items: []string{"abc", ""}, want: ""},
+		{name: "equal strings", items: []string{"abab", "abab"}, want: "abab"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := suffix_automaton.New(test.items)
+
+			assert.Equal(t, test.want, string(a.LongestCommonSubstring()))
+		})
+	}
+}
+
+func TestAutomaton_LongestCommonSubstring_Random(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		items := randomStrings(1+rand.Intn(4), 15, "ab")
+		a := suffix_automaton.New(items)
+
+		common := string(a.LongestCommonSubstring())
+
+		assert.Len(t, common, naiveLongestCommon(items), "at items: %q", items)
+		for _, item := range items {
+			assert.Contains(t, item, common, "at items: %q", items)
+		}
+	}
+}
+
+func TestAutomaton_Empty(t *testing.T) {
+	var a suffix_automaton.Automaton
+
+	assert.Equal(t, 0, a.Count())
+	assert.False(t, a.ContainsSubstring([]byte("a")))
+	assert.Empty(t, a.Occurrences([]byte("a")))
+	assert.Empty(t, a.LongestCommonSubstring())
+
+	built := suffix_automaton.New([]string{""})
+	assert.True(t, built.ContainsSubstring(nil))
+	assert.False(t, built.ContainsSubstring([]byte("a")))
+	assert.Equal(t, []int{0}, built.Occurrences(nil))
+}
+
+func BenchmarkAutomaton_Build(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	var a *suffix_automaton.Automaton
+	for i := 0; i < b.N; i++ {
+		a = suffix_automaton.New(cities)
+	}
+	b.ReportMetric(float64(a.States()), "states")
+	b.ReportMetric(float64(a.Transitions()), "transitions")
+}
+
+// BenchmarkAutomaton_Occurrences сравнивает поиск городов, содержащих "burg",
+// с последовательным просмотром всех названий.
+func BenchmarkAutomaton_Occurrences(b *testing.B) {
+	cities := fixtures.CitiesT(b)
+	a := suffix_automaton.New(cities)
+	pattern := "burg"
+	b.ResetTimer()
+
+	b.Run("automaton", func(b *testing.B) {
+		var found []int
+		for i := 0; i < b.N; i++ {
+			found = a.Occurrences([]byte(pattern))
+		}
+		b.ReportMetric(float64(len(found)), "cities")
+	})
+	b.Run("strings.Contains", func(b *testing.B) {
+		var found []int
+		for i := 0; i < b.N; i++ {
+			found = naiveOccurrences(cities, pattern)
+		}
+		b.ReportMetric(float64(len(found)), "cities")
+	})
+}
+
+func naiveOccurrences(items []string, pattern string) []int {
+	var indices []int
+	for i, item := range items {
+		if strings.Contains(item, pattern) {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+// naiveLongestCommon возвращает длину самой длинной общей подстроки перебором
+// подстрок первой строки.
+func naiveLongestCommon(items []string) int {
+	best := 0
+	for i := 0; i < len(items[0]); i++ {
+		for j := i + best + 1; j <= len(items[0]); j++ {
+			pattern := []byte(items[0][i:j])
+			common := true
+			for _, item := range items[1:] {
+				if !bytes.Contains([]byte(item), pattern) {
+					common = false
+					break
+				}
+			}
+			if !common {
+				break
+			}
+			best = j - i
+		}
+	}
+
+	return best
+}
+
+func randomStrings(count, maxLength int, alphabet string) []string {
+	items := make([]string, count)
+	for i := range items {
+		item := make([]byte, rand.Intn(maxLength+1))
+		for j := range item {
+			item[j] = alphabet[rand.Intn(len(alphabet))]
+		}
+		items[i] = string(item)
+	}
+
+	return items
+}
@@ -0,0 +1,195 @@
+package suffix_automaton
+
+// denseDegree - количество переходов, при котором состояние переводится на таблицу переходов.
+const denseDegree = 8
+
+// Builder строит обобщенный суффиксный автомат набора строк онлайн-алгоритмом
+// Blumer и др. Строки добавляются по одной, после каждой строки построение
+// продолжается из начального состояния, поэтому переход по уже существующему
+// пути может потребовать разделения (клонирования) состояния.
+//
+// Переходы состояний хранятся односвязными списками в общих массивах. Состояния
+// с большим количеством переходов (в том числе начальное, через которое проходит
+// большинство цепочек суффиксных ссылок) переводятся на таблицы переходов по всем
+// значениям байта, так как поиск в длинном списке занимает большую часть построения.
+type Builder struct {
+	// состояния: длина самой длинной подстроки, суффиксная ссылка, первый переход списка
+	// и номер таблицы переходов (-1 - переходы хранятся списком)
+	length []int32
+	link   []int32
+	first  []int32
+	dense  []int32
+	// переходы: байт, целевое состояние, следующий переход списка
+	labels   []byte
+	targets  []int32
+	nextEdge []int32
+	tables   [][256]int32
+	// буфер переходов клонируемого состояния
+	scratch []edge
+	// состояния после каждого префикса каждой строки
+	prefixes []int32
+	// концы диапазонов строк в prefixes
+	ends []int32
+}
+
+// NewBuilder создает построитель автомата без строк.
+func NewBuilder() *Builder {
+	b := &Builder{}
+	b.makeDense(b.addState(0, -1))
+
+	return b
+}
+
+// Add добавляет строку s. Строки нумеруются с нуля в порядке добавления.
+func (b *Builder) Add(s []byte) {
+	last := int32(0)
+	for _, c := range s {
+		last = b.extend(last, c)
+		b.prefixes = append(b.prefixes, last)
+	}
+	b.ends = append(b.ends, int32(len(b.prefixes)))
+}
+
+// Finish завершает построение и возвращает автомат. После вызова построитель
+// использовать нельзя.
+func (b *Builder) Finish() *Automaton {
+	a := freeze(b)
+	*b = Builder{}
+
+	return a
+}
+
+// extend добавляет к подстрокам состояния last байт c и возвращает состояние
+// полученного префикса строки.
+func (b *Builder) extend(last int32, c byte) int32 {
+	if q := b.next(last, c); q >= 0 {
+		// префикс уже встречался в предыдущих строках
+		if b.length[last]+1 == b.length[q] {
+			return q
+		}
+		return b.split(last, q, c)
+	}
+
+	current := b.addState(b.length[last]+1, 0)
+	p := last
+	for p >= 0 && b.next(p, c) < 0 {
+		b.setNext(p, c, current)
+		p = b.link[p]
+	}
+	if p < 0 {
+		return current
+	}
+
+	q := b.next(p, c)
+	if b.length[p]+1 == b.length[q] {
+		b.link[current] = q
+	} else {
+		b.link[current] = b.split(p, q, c)
+	}
+
+	return current
+}
+
+// split отделяет от состояния q подстроки длиной не более length[p]+1 в новое
+// состояние и перенаправляет на него переходы по байту c из p и его суффиксов.
+func (b *Builder) split(p, q int32, c byte) int32 {
+	clone := b.addState(b.length[p]+1, b.link[q])
+	b.scratch = b.edges(q, b.scratch[:0])
+	for _, e := range b.scratch {
+		b.setNext(clone, e.label, e.target)
+	}
+	for p >= 0 && b.next(p, c) == q {
+		b.setNext(p, c, clone)
+		p = b.link[p]
+	}
+	b.link[q] = clone
+
+	return clone
+}
+
+func (b *Builder) addState(length, link int32) int32 {
+	b.length = append(b.length, length)
+	b.link = append(b.link, link)
+	b.first = append(b.first, -1)
+	b.dense = append(b.dense, -1)
+
+	return int32(len(b.length) - 1)
+}
+
+// next возвращает состояние перехода из s по байту c или -1, если перехода нет.
+func (b *Builder) next(s int32, c byte) int32 {
+	if d := b.dense[s]; d >= 0 {
+		return b.tables[d][c]
+	}
+	for e := b.first[s]; e >= 0; e = b.nextEdge[e] {
+		if b.labels[e] == c {
+			return b.targets[e]
+		}
+	}
+
+	return -1
+}
+
+func (b *Builder) setNext(s int32, c byte, target int32) {
+	if d := b.dense[s]; d >= 0 {
+		b.tables[d][c] = target
+		return
+	}
+
+	degree := 0
+	for e := b.first[s]; e >= 0; e = b.nextEdge[e] {
+		if b.labels[e] == c {
+			b.targets[e] = target
+			return
+		}
+		degree++
+	}
+
+	// переход добавляется в начало списка
+	b.labels = append(b.labels, c)
+	b.targets = append(b.targets, target)
+	b.nextEdge = append(b.nextEdge, b.first[s])
+	b.first[s] = int32(len(b.labels) - 1)
+	if degree+1 >= denseDegree {
+		b.makeDense(s)
+	}
+}
+
+// makeDense переводит переходы состояния s на таблицу переходов. Элементы списка
+// переходов при этом не освобождаются.
+func (b *Builder) makeDense(s int32) {
+	var table [256]int32
+	for i := range table {
+		table[i] = -1
+	}
+	for e := b.first[s]; e >= 0; e = b.nextEdge[e] {
+		table[b.labels[e]] = b.targets[e]
+	}
+	b.first[s] = -1
+	b.dense[s] = int32(len(b.tables))
+	b.tables = append(b.tables, table)
+}
+
+type edge struct {
+	label  byte
+	target int32
+}
+
+// edges добавляет к dst переходы состояния s. Переходы таблицы перечисляются
+// в порядке возрастания байт, переходы списка - в обратном порядке добавления.
+func (b *Builder) edges(s int32, dst []edge) []edge {
+	if d := b.dense[s]; d >= 0 {
+		for c, target := range &b.tables[d] {
+			if target >= 0 {
+				dst = append(dst, edge{label: byte(c), target: target})
+			}
+		}
+		return dst
+	}
+
+	for e := b.first[s]; e >= 0; e = b.nextEdge[e] {
+		dst = append(dst, edge{label: b.labels[e], target: b.targets[e]})
+	}
+
+	return dst
+}
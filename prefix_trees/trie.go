@@ -0,0 +1,133 @@
+package prefix_trees
+
+import (
+	"encoding"
+	"encoding/json"
+	"io"
+)
+
+// Key - допустимые типы ключей префиксного дерева: байтовые ключи и строки.
+type Key interface {
+	~[]byte | ~string
+}
+
+// Reader - операции чтения префиксного дерева.
+type Reader[K Key, V any] interface {
+	json.Marshaler
+
+	// Count возвращает количество значений в дереве.
+	Count() int
+	// Get возвращает значение по ключу или нулевое значение, если ключ не найден.
+	Get(key K) V
+	// Find возвращает значение по ключу и флаг его наличия в дереве.
+	Find(key K) (V, bool)
+	// Walk перебирает дерево и для каждого существующего значения вызывает функцию f.
+	// Перебор прерывается при первой ошибке, которая возвращается из метода.
+	Walk(f func(key K, value V) error) error
+	// WalkPrefix перебирает значения, ключи которых начинаются с префикса prefix
+	// (включая сам префикс), и для каждого из них вызывает функцию f.
+	WalkPrefix(prefix K, f func(key K, value V) error) error
+	// KeysWithPrefix возвращает ключи, начинающиеся с префикса prefix. Если limit
+	// больше нуля, то возвращается не более limit ключей.
+	KeysWithPrefix(prefix K, limit int) []K
+}
+
+// Trie - общий контракт ассоциативного массива на основе префиксного дерева.
+// Реализуется всеми изменяемыми вариантами деревьев: alphabet_trie.Array64, byte_trie.Array,
+// byte_shard_trie.Array, byte_suffix_trie.Array, double_array_trie.Array и art.Tree.
+type Trie[K Key, V any] interface {
+	Reader[K, V]
+
+	// Put сохраняет значение по ключу, заменяя существующее.
+	Put(key K, value V)
+	// Delete удаляет значение по ключу.
+	Delete(key K)
+}
+
+// LongestPrefixMatcher - дерево с поиском самого длинного хранящегося ключа,
+// являющегося префиксом заданного ключа.
+type LongestPrefixMatcher[K Key, V any] interface {
+	// LongestPrefix возвращает длину самого длинного ключа, являющегося префиксом
+	// ключа key, его значение и флаг наличия.
+	LongestPrefix(key K) (matchedLen int, value V, ok bool)
+}
+
+// Navigator - дерево с навигацией по упорядоченным ключам, как в упорядоченном
+// ассоциативном массиве. Каждый метод возвращает найденный ключ, его значение
+// и флаг наличия.
+type Navigator[K Key, V any] interface {
+	// Min возвращает наименьший ключ.
+	Min() (K, V, bool)
+	// Max возвращает наибольший ключ.
+	Max() (K, V, bool)
+	// Floor возвращает наибольший ключ, меньший или равный key.
+	Floor(key K) (K, V, bool)
+	// Ceiling возвращает наименьший ключ, больший или равный key.
+	Ceiling(key K) (K, V, bool)
+	// Predecessor возвращает наибольший ключ, строго меньший key.
+	Predecessor(key K) (K, V, bool)
+	// Successor возвращает наименьший ключ, строго больший key.
+	Successor(key K) (K, V, bool)
+}
+
+// Cursor - курсор для перебора значений дерева в порядке ключей с произвольной
+// начальной позиции. После изменения дерева курсор необходимо заново
+// позиционировать методами First, Last или Seek.
+type Cursor[K Key, V any] interface {
+	// Valid возвращает true, если курсор указывает на существующее значение.
+	Valid() bool
+	// Key возвращает ключ текущего значения. Ключ действителен до следующего
+	// перемещения курсора.
+	Key() K
+	// Value возвращает текущее значение.
+	Value() V
+	// First перемещает курсор на наименьший ключ.
+	First() bool
+	// Last перемещает курсор на наибольший ключ.
+	Last() bool
+	// Seek перемещает курсор на наименьший ключ, больший или равный key.
+	Seek(key K) bool
+	// Next перемещает курсор на следующий ключ.
+	Next() bool
+	// Prev перемещает курсор на предыдущий ключ.
+	Prev() bool
+}
+
+// Ranger - дерево с перебором значений в диапазоне ключей, упорядоченных
+// по возрастанию байт.
+type Ranger[V any] interface {
+	// Range перебирает значения с ключами в диапазоне от from до to
+	// и для каждого из них вызывает функцию f.
+	Range(from, to Bound, f func(key []byte, value V) error) error
+}
+
+// FuzzySearcher - дерево с нечетким поиском ключей по расстоянию Левенштейна.
+type FuzzySearcher[K Key, V any] interface {
+	// FuzzySearch перебирает значения, ключи которых находятся на расстоянии
+	// не более maxDistance от ключа key, и для каждого из них вызывает функцию f.
+	FuzzySearch(key K, maxDistance int, f func(key K, value V, distance int) error) error
+}
+
+// PatternMatcher - дерево с поиском ключей по шаблону с элементами '?', '*'
+// и классами символов '[...]'.
+type PatternMatcher[K Key, V any] interface {
+	// Match перебирает значения, ключи которых соответствуют шаблону pattern,
+	// и для каждого из них вызывает функцию f.
+	Match(pattern K, f func(key K, value V) error) error
+}
+
+// BinarySerializer - дерево с бинарной сериализацией структуры узлов.
+// Значения кодируются с помощью ValueCodec.
+type BinarySerializer interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// JSONStreamer - дерево с потоковым кодированием в JSON. Дерево записывается
+// объектом, ключи которого - ключи дерева, а значения - значения дерева.
+type JSONStreamer interface {
+	// EncodeJSON записывает дерево в w в порядке перебора ключей.
+	EncodeJSON(w io.Writer, options ...JSONOption) error
+	// DecodeJSON читает объект JSON из r и добавляет его элементы в дерево.
+	DecodeJSON(r io.Reader, options ...JSONOption) error
+}
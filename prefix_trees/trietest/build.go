@@ -0,0 +1,132 @@
+package trietest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+)
+
+// RunBuild запускает сценарии построения дерева из упорядоченных ключей функцией
+// build (например, BuildFromSorted). Порядок ключей определяется перебором
+// методом Walk дерева, создаваемого функцией newTrie: построенное дерево должно
+// совпадать с заполненным методом Put, а для неупорядоченных или повторяющихся
+// ключей функция build должна возвращать ошибку errUnsorted.
+func RunBuild[K prefix_trees.Key](
+	t *testing.T,
+	newTrie func() prefix_trees.Trie[K, int],
+	build func(seq prefix_trees.Seq[K, int]) (prefix_trees.Trie[K, int], error),
+	errUnsorted error,
+) {
+	t.Helper()
+
+	t.Run("same as put", func(t *testing.T) {
+		items := newTrie()
+		items.Put(K(""), -1)
+		for _, key := range randomStrings(7, 3000) {
+			items.Put(K(key), len(key))
+		}
+
+		built, err := build(items.Walk)
+
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, items.Count(), built.Count())
+		assert.Equal(t, walkItems(items), walkItems(built))
+		for _, prefix := range []string{"A", "Ba", "e", "zz"} {
+			assert.Equal(t, items.KeysWithPrefix(K(prefix), 0), built.KeysWithPrefix(K(prefix), 0), "at prefix: %s", prefix)
+		}
+
+		// построенное дерево остается изменяемым
+		built.Put(K("Aa"), 100)
+		built.Delete(K("A"))
+		items.Put(K("Aa"), 100)
+		items.Delete(K("A"))
+		assert.Equal(t, walkItems(items), walkItems(built))
+	})
+	t.Run("empty", func(t *testing.T) {
+		built, err := build(prefix_trees.SliceSeq[K, int](nil, nil))
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, 0, built.Count())
+			assert.Empty(t, walkKeys(built))
+		}
+	})
+	t.Run("slice seq", func(t *testing.T) {
+		countries := newTrie()
+		for _, country := range uniqueCountries() {
+			countries.Put(K(country), len(country))
+		}
+		var keys []K
+		var values []int
+		_ = countries.Walk(func(key K, value int) error {
+			keys = append(keys, K(string(key)))
+			values = append(values, value)
+			return nil
+		})
+
+		built, err := build(prefix_trees.SliceSeq(keys, values))
+
+		if assert.NoError(t, err) {
+			assert.Equal(t, len(keys), built.Count())
+			assert.Equal(t, walkItems(countries), walkItems(built))
+		}
+	})
+	t.Run("unsorted", func(t *testing.T) {
+		// порядок пары ключей определяется деревом, а не порядком байт
+		pair := newTrie()
+		pair.Put(K("a"), 0)
+		pair.Put(K("B"), 0)
+		ordered := walkKeys(pair)
+
+		tests := []struct {
+			name string
+			keys []string
+		}{
+			{name: "reversed", keys: []string{ordered[1], ordered[0]}},
+			{name: "duplicate", keys: []string{"a", "a"}},
+			{name: "prefix after key", keys: []string{"ab", "a"}},
+			{name: "empty key after key", keys: []string{"a", ""}},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				keys := make([]K, len(test.keys))
+				for i, key := range test.keys {
+					keys[i] = K(key)
+				}
+
+				_, err := build(prefix_trees.SliceSeq(keys, make([]int, len(keys))))
+
+				assert.ErrorIs(t, err, errUnsorted)
+			})
+		}
+	})
+	t.Run("sequence error", func(t *testing.T) {
+		errSeq := errors.New("sequence error")
+
+		_, err := build(func(f func(key K, value int) error) error {
+			return errSeq
+		})
+
+		assert.ErrorIs(t, err, errSeq)
+	})
+}
+
+type item struct {
+	key   string
+	value int
+}
+
+// walkItems возвращает ключи и значения дерева в порядке перебора.
+func walkItems[K prefix_trees.Key](tree prefix_trees.Trie[K, int]) []item {
+	var items []item
+	_ = tree.Walk(func(key K, value int) error {
+		items = append(items, item{key: string(key), value: value})
+
+		return nil
+	})
+
+	return items
+}
@@ -0,0 +1,191 @@
+package trietest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+// RunCursor запускает сценарии курсора для дерева, создаваемого функцией newTrie.
+// Функция cursor создает курсор по дереву. Порядок ключей курсора должен
+// совпадать с порядком перебора методом Walk.
+func RunCursor[K prefix_trees.Key](
+	t *testing.T,
+	newTrie func() prefix_trees.Trie[K, int],
+	cursor func(trie prefix_trees.Trie[K, int]) prefix_trees.Cursor[K, int],
+) {
+	t.Helper()
+
+	t.Run("empty", func(t *testing.T) {
+		c := cursor(newTrie())
+
+		assert.False(t, c.First())
+		assert.False(t, c.Last())
+		assert.False(t, c.Seek(K("a")))
+		assert.False(t, c.Valid())
+		assert.Empty(t, c.Key())
+		assert.Equal(t, 0, c.Value())
+	})
+	t.Run("iterate", func(t *testing.T) {
+		trie := cursorTrie(newTrie())
+		keys := walkKeys(trie)
+		c := cursor(trie)
+
+		var forward []string
+		for ok := c.First(); ok; ok = c.Next() {
+			forward = append(forward, string(c.Key()))
+			assert.Equal(t, trie.Get(c.Key()), c.Value())
+		}
+		var backward []string
+		for ok := c.Last(); ok; ok = c.Prev() {
+			backward = append([]string{string(c.Key())}, backward...)
+		}
+
+		assert.Equal(t, keys, forward)
+		assert.Equal(t, keys, backward)
+	})
+	t.Run("seek", func(t *testing.T) {
+		testSeek(t, cursorTrie(newTrie()), cursor)
+	})
+}
+
+func testSeek[K prefix_trees.Key](
+	t *testing.T,
+	trie prefix_trees.Trie[K, int],
+	cursor func(trie prefix_trees.Trie[K, int]) prefix_trees.Cursor[K, int],
+) {
+	keys := walkKeys(trie)
+	exists := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		exists[key] = true
+	}
+	probes := append([]string{"", "A", "Zz", "zzz", "Congo", "Congo Zaire", "United"}, keys...)
+	probes = append(probes, randomStrings(7, 1000)...)
+
+	for _, probe := range probes {
+		// позиция искомого ключа определяется порядком перебора дерева
+		// с временно добавленным ключом
+		i := 0
+		if !exists[probe] {
+			trie.Put(K(probe), 0)
+			for _, key := range walkKeys(trie) {
+				if key == probe {
+					break
+				}
+				i++
+			}
+			trie.Delete(K(probe))
+		} else {
+			for keys[i] != probe {
+				i++
+			}
+		}
+
+		c := cursor(trie)
+		if i == len(keys) {
+			assert.False(t, c.Seek(K(probe)), "at probe: %q", probe)
+			assert.False(t, c.Valid())
+			continue
+		}
+		if assert.True(t, c.Seek(K(probe)), "at probe: %q", probe) {
+			assert.Equal(t, keys[i], string(c.Key()), "at probe: %q", probe)
+		}
+		if i+1 < len(keys) && assert.True(t, c.Next()) {
+			assert.Equal(t, keys[i+1], string(c.Key()))
+			c.Prev()
+		}
+		if i > 0 && assert.True(t, c.Prev()) {
+			assert.Equal(t, keys[i-1], string(c.Key()))
+		}
+	}
+}
+
+func testRange[K prefix_trees.Key](t *testing.T, trie prefix_trees.Trie[K, int]) {
+	items, ok := trie.(prefix_trees.Ranger[int])
+	if !ok {
+		t.Skip("range is not implemented")
+	}
+	keys := walkKeys(cursorTrie(trie))
+
+	tests := []struct {
+		name     string
+		from, to prefix_trees.Bound
+		want     func(key string) bool
+	}{
+		{
+			name: "unbounded",
+			from: prefix_trees.Unbounded(),
+			to:   prefix_trees.Unbounded(),
+			want: func(key string) bool { return true },
+		},
+		{
+			name: "inclusive",
+			from: prefix_trees.Inclusive([]byte("Chad")),
+			to:   prefix_trees.Inclusive([]byte("Cuba")),
+			want: func(key string) bool { return key >= "Chad" && key <= "Cuba" },
+		},
+		{
+			name: "exclusive",
+			from: prefix_trees.Exclusive([]byte("Chad")),
+			to:   prefix_trees.Exclusive([]byte("Cuba")),
+			want: func(key string) bool { return key > "Chad" && key < "Cuba" },
+		},
+		{
+			name: "missing bounds",
+			from: prefix_trees.Exclusive([]byte("Ca")),
+			to:   prefix_trees.Inclusive([]byte("Cz")),
+			want: func(key string) bool { return key > "Ca" && key <= "Cz" },
+		},
+		{
+			name: "from only",
+			from: prefix_trees.Inclusive([]byte("United")),
+			to:   prefix_trees.Unbounded(),
+			want: func(key string) bool { return key >= "United" },
+		},
+		{
+			name: "to only",
+			from: prefix_trees.Unbounded(),
+			to:   prefix_trees.Exclusive([]byte("B")),
+			want: func(key string) bool { return key < "B" },
+		},
+		{
+			name: "empty",
+			from: prefix_trees.Inclusive([]byte("Cuba")),
+			to:   prefix_trees.Exclusive([]byte("Chad")),
+			want: func(key string) bool { return false },
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var want []string
+			for _, key := range keys {
+				if test.want(key) {
+					want = append(want, key)
+				}
+			}
+
+			var got []string
+			err := items.Range(test.from, test.to, func(key []byte, value int) error {
+				got = append(got, string(key))
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+// cursorTrie заполняет дерево странами и ключами с общими префиксами.
+func cursorTrie[K prefix_trees.Key](trie prefix_trees.Trie[K, int]) prefix_trees.Trie[K, int] {
+	for i, country := range fixtures.Countries {
+		trie.Put(K(country), i+1)
+	}
+	for _, key := range []string{"C", "Co", "Congo Zaire", "Cuba Libre"} {
+		trie.Put(K(key), 0)
+	}
+
+	return trie
+}
@@ -0,0 +1,795 @@
+// Package trietest содержит общий набор поведенческих проверок для реализаций
+// интерфейса prefix_trees.Trie. Каждая реализация запускает одни и те же сценарии
+// с помощью функции Run, сценарии курсора - с помощью функции RunCursor,
+// а сценарии построения из упорядоченных ключей - с помощью функции RunBuild.
+package trietest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strider2038/algos/prefix_trees"
+	"github.com/strider2038/algos/testdata/fixtures"
+)
+
+// Alphabet - набор символов, из которых состоят ключи всех сценариев. Деревья
+// с ограниченным алфавитом должны поддерживать как минимум эти символы.
+const Alphabet = `abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ "-&`
+
+// Run запускает все сценарии для дерева, создаваемого функцией newTrie.
+// Каждый сценарий получает новый пустой экземпляр дерева.
+func Run[K prefix_trees.Key](t *testing.T, newTrie func() prefix_trees.Trie[K, int]) {
+	t.Helper()
+
+	t.Run("basic", func(t *testing.T) {
+		testBasic(t, newTrie())
+	})
+	t.Run("overwrite", func(t *testing.T) {
+		testOverwrite(t, newTrie())
+	})
+	t.Run("empty key", func(t *testing.T) {
+		testEmptyKey(t, newTrie())
+	})
+	t.Run("find prefix of key", func(t *testing.T) {
+		testFindPrefix(t, newTrie())
+	})
+	t.Run("delete prefix of key", func(t *testing.T) {
+		testDeletePrefix(t, newTrie())
+	})
+	t.Run("delete all", func(t *testing.T) {
+		testDeleteAll(t, newTrie())
+	})
+	t.Run("countries", func(t *testing.T) {
+		testCountries(t, newTrie())
+	})
+	t.Run("random strings", func(t *testing.T) {
+		testRandomStrings(t, newTrie())
+	})
+	t.Run("random delete", func(t *testing.T) {
+		testRandomDelete(t, newTrie())
+	})
+	t.Run("walk prefix", func(t *testing.T) {
+		testWalkPrefix(t, newTrie())
+	})
+	t.Run("walk prefix inside key", func(t *testing.T) {
+		testWalkPrefixInsideKey(t, newTrie())
+	})
+	t.Run("keys with prefix", func(t *testing.T) {
+		testKeysWithPrefix(t, newTrie())
+	})
+	t.Run("longest prefix", func(t *testing.T) {
+		testLongestPrefix(t, newTrie())
+	})
+	t.Run("navigation", func(t *testing.T) {
+		testNavigation(t, newTrie())
+	})
+	t.Run("range", func(t *testing.T) {
+		testRange(t, newTrie())
+	})
+	t.Run("fuzzy search", func(t *testing.T) {
+		testFuzzySearch(t, newTrie())
+	})
+	t.Run("pattern match", func(t *testing.T) {
+		testMatch(t, newTrie())
+	})
+	t.Run("walk error", func(t *testing.T) {
+		testWalkError(t, newTrie())
+	})
+	t.Run("marshal json", func(t *testing.T) {
+		testMarshalJSON(t, newTrie())
+	})
+	t.Run("unmarshal json", func(t *testing.T) {
+		testUnmarshalJSON(t, newTrie(), newTrie())
+	})
+	t.Run("stream json", func(t *testing.T) {
+		testStreamJSON(t, newTrie, newTrie)
+	})
+	t.Run("marshal binary", func(t *testing.T) {
+		testMarshalBinary(t, newTrie(), newTrie())
+	})
+}
+
+func testBasic[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K("alpha"), 1)
+	items.Put(K("beta"), 2)
+	items.Put(K("gamma"), 3)
+	items.Put(K("delta"), 4)
+	items.Delete(K("beta"))
+	items.Put(K("beta"), 5)
+	items.Put(K("cap"), 6)
+	items.Put(K("cat"), 7)
+	items.Put(K("car"), 8)
+	items.Delete(K("delta"))
+	items.Delete(K("delta"))
+	items.Delete(K("unknown"))
+
+	assert.Equal(t, 6, items.Count())
+	assert.Equal(t, 1, items.Get(K("alpha")))
+	assert.Equal(t, 5, items.Get(K("beta")))
+	assert.Equal(t, 3, items.Get(K("gamma")))
+	assert.Equal(t, 6, items.Get(K("cap")))
+	assert.Equal(t, 7, items.Get(K("cat")))
+	assert.Equal(t, 8, items.Get(K("car")))
+	assert.Equal(t, 0, items.Get(K("delta")))
+	if _, exist := items.Find(K("delta")); exist {
+		t.Error("delta value is found in map")
+	}
+}
+
+func testOverwrite[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K("key"), 1)
+	items.Put(K("key"), 2)
+
+	assert.Equal(t, 1, items.Count())
+	assert.Equal(t, 2, items.Get(K("key")))
+}
+
+func testEmptyKey[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K(""), 1)
+	items.Put(K("a"), 2)
+
+	assert.Equal(t, 2, items.Count())
+	assert.Equal(t, 1, items.Get(K("")))
+	assertWalkEqual(t, map[string]int{"": 1, "a": 2}, items)
+
+	items.Delete(K(""))
+
+	assert.Equal(t, 1, items.Count())
+	assertWalkEqual(t, map[string]int{"a": 2}, items)
+}
+
+func testFindPrefix[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K("abc"), 1)
+
+	for _, key := range []string{"", "a", "ab", "abcd", "b"} {
+		if _, exist := items.Find(K(key)); exist {
+			t.Errorf("key %q is found in map", key)
+		}
+	}
+	assert.Equal(t, 1, items.Get(K("abc")))
+}
+
+func testDeletePrefix[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K("car"), 1)
+	items.Put(K("cart"), 2)
+	items.Delete(K("ca"))
+	items.Delete(K("carts"))
+
+	assert.Equal(t, 2, items.Count())
+	assert.Equal(t, 1, items.Get(K("car")))
+	assert.Equal(t, 2, items.Get(K("cart")))
+
+	items.Delete(K("car"))
+
+	assert.Equal(t, 1, items.Count())
+	assert.Equal(t, 2, items.Get(K("cart")))
+	if _, exist := items.Find(K("car")); exist {
+		t.Error("car value is found in map")
+	}
+}
+
+func testDeleteAll[K prefix_trees.Key](t *testing.T, countries prefix_trees.Trie[K, int]) {
+	keys := uniqueCountries()
+	for i, country := range keys {
+		countries.Put(K(country), i+1)
+	}
+	// удаляем каждую вторую страну, затем оставшиеся
+	for i := 0; i < len(keys); i += 2 {
+		countries.Delete(K(keys[i]))
+	}
+	for i, country := range keys {
+		if _, exist := countries.Find(K(country)); exist != (i%2 == 1) {
+			t.Errorf("unexpected presence of key %s: %v", country, exist)
+		}
+	}
+	for i := 1; i < len(keys); i += 2 {
+		countries.Delete(K(keys[i]))
+	}
+
+	assert.Equal(t, 0, countries.Count())
+	assertWalkEqual(t, map[string]int{}, countries)
+
+	countries.Put(K("alpha"), 1)
+
+	assert.Equal(t, 1, countries.Count())
+	assert.Equal(t, 1, countries.Get(K("alpha")))
+}
+
+func testCountries[K prefix_trees.Key](t *testing.T, countries prefix_trees.Trie[K, int]) {
+	m := map[string]int{}
+
+	for i, country := range fixtures.Countries {
+		countries.Put(K(country), i+1)
+		m[country] = i + 1
+	}
+
+	assert.Equal(t, len(m), countries.Count())
+	assertWalkEqual(t, m, countries)
+}
+
+func testRandomStrings[K prefix_trees.Key](t *testing.T, tree prefix_trees.Trie[K, int]) {
+	const count = 100_000
+	m := map[string]int{}
+
+	ss := randomStrings(15, count)
+	for i, s := range ss {
+		tree.Put(K(s), i)
+		m[s] = i
+	}
+
+	assert.Equal(t, len(m), tree.Count())
+	for key, value := range m {
+		v, ok := tree.Find(K(key))
+		if !ok {
+			t.Error("key not found:", key)
+		}
+		assert.Equal(t, value, v, "at key: %s", key)
+	}
+}
+
+func testRandomDelete[K prefix_trees.Key](t *testing.T, tree prefix_trees.Trie[K, int]) {
+	const count = 10_000
+	m := map[string]int{}
+
+	ss := randomStrings(10, count)
+	for i, s := range ss {
+		tree.Put(K(s), i)
+		m[s] = i
+	}
+	for i, s := range ss {
+		if i%3 != 0 {
+			tree.Delete(K(s))
+			delete(m, s)
+		}
+	}
+
+	assert.Equal(t, len(m), tree.Count())
+	for _, s := range ss {
+		value, exists := m[s]
+		v, ok := tree.Find(K(s))
+		if ok != exists {
+			t.Errorf("unexpected presence of key %s: %v", s, ok)
+		}
+		assert.Equal(t, value, v, "at key: %s", s)
+	}
+	assertWalkEqual(t, m, tree)
+}
+
+func testWalkPrefix[K prefix_trees.Key](t *testing.T, countries prefix_trees.Trie[K, int]) {
+	m := map[string]int{}
+	for i, country := range fixtures.Countries {
+		countries.Put(K(country), i+1)
+		m[country] = i + 1
+	}
+
+	for _, prefix := range []string{"", "B", "Gu", "Guinea", "Congo", "Zimbabwe", "Zimbabwez", "Q", "x"} {
+		t.Run(prefix, func(t *testing.T) {
+			want := map[string]int{}
+			for key, value := range m {
+				if strings.HasPrefix(key, prefix) {
+					want[key] = value
+				}
+			}
+
+			visited := map[string]int{}
+			err := countries.WalkPrefix(K(prefix), func(key K, value int) error {
+				visited[string(key)] = value
+
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, visited)
+		})
+	}
+}
+
+func testWalkPrefixInsideKey[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K("abcdef"), 1)
+	items.Put(K("abxy"), 2)
+
+	assert.Equal(t, []K{K("abcdef")}, items.KeysWithPrefix(K("abc"), 0))
+	assert.Equal(t, []K{K("abcdef")}, items.KeysWithPrefix(K("abcdef"), 0))
+	assert.Equal(t, []K{K("abcdef"), K("abxy")}, items.KeysWithPrefix(K("a"), 0))
+	assert.Empty(t, items.KeysWithPrefix(K("abcdefg"), 0))
+	assert.Empty(t, items.KeysWithPrefix(K("abd"), 0))
+}
+
+func testKeysWithPrefix[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K("car"), 1)
+	items.Put(K("cart"), 2)
+	items.Put(K("carts"), 3)
+	items.Put(K("cat"), 4)
+	items.Put(K("dog"), 5)
+
+	assert.Equal(t, []K{K("car"), K("cart"), K("carts"), K("cat")}, items.KeysWithPrefix(K("ca"), 0))
+	assert.Equal(t, []K{K("car"), K("cart")}, items.KeysWithPrefix(K("ca"), 2))
+	assert.Equal(t, []K{K("cart"), K("carts")}, items.KeysWithPrefix(K("cart"), 10))
+	assert.Empty(t, items.KeysWithPrefix(K("cow"), 0))
+}
+
+func testLongestPrefix[K prefix_trees.Key](t *testing.T, trie prefix_trees.Trie[K, int]) {
+	items, ok := trie.(prefix_trees.LongestPrefixMatcher[K, int])
+	if !ok {
+		t.Skip("LongestPrefix is not implemented")
+	}
+
+	trie.Put(K("a"), 1)
+	trie.Put(K("abc"), 2)
+	trie.Put(K("abcdef"), 3)
+	trie.Put(K("b"), 4)
+	trie.Put(K("bcdxyz"), 5)
+
+	tests := []struct {
+		key       string
+		wantLen   int
+		wantValue int
+		wantOK    bool
+	}{
+		{key: "", wantOK: false},
+		{key: "x", wantOK: false},
+		{key: "a", wantLen: 1, wantValue: 1, wantOK: true},
+		{key: "ab", wantLen: 1, wantValue: 1, wantOK: true},
+		{key: "abc", wantLen: 3, wantValue: 2, wantOK: true},
+		{key: "abcde", wantLen: 3, wantValue: 2, wantOK: true},
+		{key: "abcdef", wantLen: 6, wantValue: 3, wantOK: true},
+		{key: "abcdefgh", wantLen: 6, wantValue: 3, wantOK: true},
+		{key: "bcdxy", wantLen: 1, wantValue: 4, wantOK: true},
+		{key: "bcdxyz", wantLen: 6, wantValue: 5, wantOK: true},
+		{key: "bcdxyz!", wantLen: 6, wantValue: 5, wantOK: true},
+	}
+	for _, test := range tests {
+		matchedLen, value, ok := items.LongestPrefix(K(test.key))
+
+		assert.Equal(t, test.wantOK, ok, "at key: %s", test.key)
+		assert.Equal(t, test.wantLen, matchedLen, "at key: %s", test.key)
+		assert.Equal(t, test.wantValue, value, "at key: %s", test.key)
+	}
+
+	trie.Put(K(""), 6)
+	matchedLen, value, ok := items.LongestPrefix(K("x"))
+
+	assert.True(t, ok)
+	assert.Equal(t, 0, matchedLen)
+	assert.Equal(t, 6, value)
+}
+
+func testNavigation[K prefix_trees.Key](t *testing.T, trie prefix_trees.Trie[K, int]) {
+	items, ok := trie.(prefix_trees.Navigator[K, int])
+	if !ok {
+		t.Skip("navigation is not implemented")
+	}
+
+	_, _, ok = items.Min()
+	assert.False(t, ok)
+	_, _, ok = items.Max()
+	assert.False(t, ok)
+	_, _, ok = items.Floor(K("a"))
+	assert.False(t, ok)
+
+	m := map[string]int{}
+	for i, country := range fixtures.Countries {
+		trie.Put(K(country), i+1)
+		m[country] = i + 1
+	}
+	keys := walkKeys(trie)
+
+	check := func(name string, find func(key K) (K, int, bool), probe, wantKey string, wantOK bool) {
+		t.Helper()
+
+		key, value, ok := find(K(probe))
+		if assert.Equal(t, wantOK, ok, "%s %q", name, probe) && ok {
+			assert.Equal(t, wantKey, string(key), "%s %q", name, probe)
+			assert.Equal(t, m[wantKey], value, "%s %q", name, probe)
+		}
+	}
+
+	check("min", func(K) (K, int, bool) { return items.Min() }, "", keys[0], true)
+	check("max", func(K) (K, int, bool) { return items.Max() }, "", keys[len(keys)-1], true)
+
+	probes := append([]string{"", "A", "Z", "zzz", "Congo", "Congo Zaire", "United"}, keys...)
+	for _, key := range keys {
+		probes = append(probes, key[:len(key)-1], key+"a", key+" ")
+	}
+	probes = append(probes, randomStrings(6, 200)...)
+
+	for _, probe := range probes {
+		// позиция искомого ключа определяется порядком перебора дерева
+		// с временно добавленным ключом
+		_, exists := m[probe]
+		if !exists {
+			trie.Put(K(probe), 0)
+		}
+		withProbe := walkKeys(trie)
+		if !exists {
+			trie.Delete(K(probe))
+		}
+		i := 0
+		for withProbe[i] != probe {
+			i++
+		}
+
+		var before, after string
+		hasBefore, hasAfter := i > 0, i+1 < len(withProbe)
+		if hasBefore {
+			before = withProbe[i-1]
+		}
+		if hasAfter {
+			after = withProbe[i+1]
+		}
+
+		floor, hasFloor := before, hasBefore
+		ceiling, hasCeiling := after, hasAfter
+		if exists {
+			floor, hasFloor = probe, true
+			ceiling, hasCeiling = probe, true
+		}
+
+		check("floor", items.Floor, probe, floor, hasFloor)
+		check("ceiling", items.Ceiling, probe, ceiling, hasCeiling)
+		check("predecessor", items.Predecessor, probe, before, hasBefore)
+		check("successor", items.Successor, probe, after, hasAfter)
+	}
+}
+
+func testFuzzySearch[K prefix_trees.Key](t *testing.T, trie prefix_trees.Trie[K, int]) {
+	items, ok := trie.(prefix_trees.FuzzySearcher[K, int])
+	if !ok {
+		t.Skip("fuzzy search is not implemented")
+	}
+
+	m := map[string]int{}
+	for i, country := range fixtures.Countries {
+		trie.Put(K(country), i+1)
+		m[country] = i + 1
+	}
+	trie.Put(K(""), 0)
+	m[""] = 0
+
+	probes := []string{"", "a", "Chad", "Chda", "Gunea", "Frnace", "Ukraine", "Bosnia Herzegovina", "xyz"}
+	for _, probe := range probes {
+		for maxDistance := 0; maxDistance <= 3; maxDistance++ {
+			want := map[string]int{}
+			for key := range m {
+				if d := levenshtein(probe, key); d <= maxDistance {
+					want[key] = d
+				}
+			}
+
+			got := map[string]int{}
+			err := items.FuzzySearch(K(probe), maxDistance, func(key K, value int, distance int) error {
+				if _, exists := got[string(key)]; exists {
+					t.Errorf("key visited twice: %s", key)
+				}
+				got[string(key)] = distance
+				assert.Equal(t, m[string(key)], value)
+
+				return nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, want, got, "probe %q, max distance %d", probe, maxDistance)
+		}
+	}
+}
+
+func testMatch[K prefix_trees.Key](t *testing.T, trie prefix_trees.Trie[K, int]) {
+	items, ok := trie.(prefix_trees.PatternMatcher[K, int])
+	if !ok {
+		t.Skip("pattern matching is not implemented")
+	}
+
+	m := map[string]int{}
+	for i, country := range fixtures.Countries {
+		trie.Put(K(country), i+1)
+		m[country] = i + 1
+	}
+	trie.Put(K(""), 0)
+	m[""] = 0
+	keys := walkKeys(trie)
+
+	patterns := []string{
+		"", "*", "**", "?", "C*", "*a", "*an*", "*a*a*a*", "[ABC]*", "[A-C]?*a", "[^A-Z]*", "[^a-zA-Z ]*",
+		"G??nea", "G*nea*", "*-*", "United*", "* * *", "Chad", "Cha", "Chad?", "*[xyz]*", "[]]*", `*\-*`,
+	}
+	for _, pattern := range patterns {
+		var want []string
+		for _, key := range keys {
+			if matched, _ := path.Match(pattern, key); matched {
+				want = append(want, key)
+			}
+		}
+
+		var got []string
+		err := items.Match(K(pattern), func(key K, value int) error {
+			got = append(got, string(key))
+			assert.Equal(t, m[string(key)], value)
+
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, "pattern %q", pattern)
+	}
+
+	for _, pattern := range []string{"[abc", `abc\`, "[z-a]"} {
+		err := items.Match(K(pattern), func(key K, value int) error {
+			return nil
+		})
+
+		assert.Error(t, err, "pattern %q", pattern)
+	}
+}
+
+func testWalkError[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K("alpha"), 1)
+	items.Put(K("beta"), 2)
+	items.Put(K("gamma"), 3)
+	errStop := errors.New("stop")
+	calls := 0
+
+	err := items.Walk(func(key K, value int) error {
+		calls++
+
+		return errStop
+	})
+
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func testMarshalJSON[K prefix_trees.Key](t *testing.T, items prefix_trees.Trie[K, int]) {
+	items.Put(K("alpha"), 1)
+	items.Put(K("beta"), 2)
+	items.Put(K("gamma"), 3)
+	items.Put(K("delta"), 4)
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.JSONEq(t, `{"alpha":1,"beta":2,"delta":4,"gamma":3}`, string(data))
+}
+
+func testUnmarshalJSON[K prefix_trees.Key](t *testing.T, items, restored prefix_trees.Trie[K, int]) {
+	unmarshaler, ok := restored.(json.Unmarshaler)
+	if !ok {
+		t.Skip("json unmarshaling is not implemented")
+	}
+	m := map[string]int{"": -1, "stale": 1}
+	items.Put(K(""), -1)
+	for i, country := range uniqueCountries() {
+		items.Put(K(country), i)
+		m[country] = i
+	}
+	// существующие ключи сохраняются, как при декодировании в map
+	restored.Put(K("stale"), 1)
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = unmarshaler.UnmarshalJSON(data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(m), restored.Count())
+	assertWalkEqual(t, m, restored)
+	assert.Error(t, unmarshaler.UnmarshalJSON([]byte(`["alpha"]`)))
+	assert.Error(t, unmarshaler.UnmarshalJSON([]byte(`{"alpha":"one"}`)))
+}
+
+func testStreamJSON[K prefix_trees.Key](t *testing.T, newItems, newRestored func() prefix_trees.Trie[K, int]) {
+	if _, ok := newItems().(prefix_trees.JSONStreamer); !ok {
+		t.Skip("json streaming is not implemented")
+	}
+	m := map[string]int{"": 0, `a"b`: 1, "A & B": 2, "-": 3}
+	for i, country := range uniqueCountries() {
+		m[country] = i + len(m)
+	}
+
+	encodings := []struct {
+		name     string
+		encoding prefix_trees.KeyEncoding
+		decode   func(key string) (string, error)
+	}{
+		{
+			name:     "text",
+			encoding: prefix_trees.KeyText,
+			decode:   func(key string) (string, error) { return key, nil },
+		},
+		{
+			name:     "escaped",
+			encoding: prefix_trees.KeyEscaped,
+			decode:   func(key string) (string, error) { return key, nil },
+		},
+		{
+			name:     "base64",
+			encoding: prefix_trees.KeyBase64,
+			decode: func(key string) (string, error) {
+				k, err := base64.StdEncoding.DecodeString(key)
+				return string(k), err
+			},
+		},
+	}
+	for _, test := range encodings {
+		t.Run(test.name, func(t *testing.T) {
+			items := newItems()
+			for key, value := range m {
+				items.Put(K(key), value)
+			}
+			option := prefix_trees.WithKeyEncoding(test.encoding)
+
+			var data bytes.Buffer
+			err := items.(prefix_trees.JSONStreamer).EncodeJSON(&data, option)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// документ должен быть корректным объектом JSON
+			var decoded map[string]int
+			if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range decoded {
+				k, err := test.decode(key)
+				assert.NoError(t, err)
+				assert.Equal(t, m[k], value, "at key: %s", k)
+			}
+
+			restored := newRestored()
+			err = restored.(prefix_trees.JSONStreamer).DecodeJSON(&data, option)
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(m), restored.Count())
+			assertWalkEqual(t, m, restored)
+		})
+	}
+}
+
+func testMarshalBinary[K prefix_trees.Key](t *testing.T, items, restored prefix_trees.Trie[K, int]) {
+	serializer, ok := items.(prefix_trees.BinarySerializer)
+	if !ok {
+		t.Skip("binary serialization is not implemented")
+	}
+	m := make(map[string]int)
+	items.Put(K(""), -1)
+	m[""] = -1
+	for i, country := range uniqueCountries() {
+		items.Put(K(country), i)
+		m[country] = i
+	}
+	restored.Put(K("stale"), 1)
+
+	data, err := serializer.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = restored.(prefix_trees.BinarySerializer).UnmarshalBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// восстановленное дерево не должно ссылаться на исходные данные
+	for i := range data {
+		data[i] = 0
+	}
+
+	assert.Equal(t, len(m), restored.Count())
+	assertWalkEqual(t, m, restored)
+	restored.Put(K("Atlantis"), 1000)
+	restored.Delete(K("Chad"))
+	assert.Equal(t, 1000, restored.Get(K("Atlantis")))
+	_, found := restored.Find(K("Chad"))
+	assert.False(t, found)
+
+	data, err = serializer.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupted := append([]byte(nil), data...)
+	corrupted[len(corrupted)/2] ^= 0xFF
+	err = restored.(prefix_trees.BinarySerializer).UnmarshalBinary(corrupted)
+	assert.ErrorIs(t, err, prefix_trees.ErrCorruptedData)
+	err = restored.(prefix_trees.BinarySerializer).UnmarshalBinary(data[:len(data)/2])
+	assert.Error(t, err)
+	err = restored.(prefix_trees.BinarySerializer).UnmarshalBinary([]byte("{}"))
+	assert.ErrorIs(t, err, prefix_trees.ErrUnsupportedFormat)
+	// при ошибке дерево не изменяется
+	assert.Equal(t, 1000, restored.Get(K("Atlantis")))
+}
+
+// assertWalkEqual проверяет, что перебор дерева посещает каждый ключ из m ровно один раз.
+func assertWalkEqual[K prefix_trees.Key](t *testing.T, m map[string]int, tree prefix_trees.Trie[K, int]) {
+	t.Helper()
+
+	visited := make(map[string]bool, len(m))
+	err := tree.Walk(func(key K, value int) error {
+		k := string(key)
+		if visited[k] {
+			t.Errorf("key visited twice: %s", k)
+		}
+		visited[k] = true
+		assert.Equal(t, m[k], value, "at key: %s", k)
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(m), len(visited))
+}
+
+// walkKeys возвращает ключи дерева в порядке перебора.
+func walkKeys[K prefix_trees.Key](tree prefix_trees.Trie[K, int]) []string {
+	var keys []string
+	_ = tree.Walk(func(key K, value int) error {
+		keys = append(keys, string(key))
+
+		return nil
+	})
+
+	return keys
+}
+
+// levenshtein вычисляет расстояние Левенштейна между строками полным перебором.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	row := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			d := prev[j-1]
+			if a[i-1] != b[j-1] {
+				d++
+			}
+			if prev[j]+1 < d {
+				d = prev[j] + 1
+			}
+			if row[j-1]+1 < d {
+				d = row[j-1] + 1
+			}
+			row[j] = d
+		}
+		prev, row = row, prev
+	}
+
+	return prev[len(b)]
+}
+
+// uniqueCountries возвращает список стран без повторов в исходном порядке.
+func uniqueCountries() []string {
+	seen := make(map[string]bool, len(fixtures.Countries))
+	countries := make([]string, 0, len(fixtures.Countries))
+	for _, country := range fixtures.Countries {
+		if !seen[country] {
+			seen[country] = true
+			countries = append(countries, country)
+		}
+	}
+
+	return countries
+}
+
+func randomStrings(maxLength, count int) []string {
+	// буквы и пробел из общего алфавита
+	chars := []byte(Alphabet[:len(Alphabet)-3])
+	ss := make([]string, count)
+	for i := range ss {
+		b := make([]byte, rand.Intn(maxLength)+1)
+		for j := range b {
+			b[j] = chars[rand.Intn(len(chars))]
+		}
+		ss[i] = string(b)
+	}
+
+	return ss
+}